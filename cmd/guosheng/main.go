@@ -16,8 +16,13 @@ import (
 	"github.com/axatol/guosheng/pkg/cmds"
 	"github.com/axatol/guosheng/pkg/config"
 	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/axatol/guosheng/pkg/playlists"
 	"github.com/axatol/guosheng/pkg/server"
 	"github.com/axatol/guosheng/pkg/server/handlers"
+	"github.com/axatol/guosheng/pkg/soundboard"
+	"github.com/axatol/guosheng/pkg/source"
+	"github.com/axatol/guosheng/pkg/sponsorblock"
 	"github.com/axatol/guosheng/pkg/yt"
 	"github.com/rs/zerolog/log"
 )
@@ -43,11 +48,12 @@ func main() {
 	}
 
 	cli := cli.Executor{
-		YTDLPExecutable:  config.YTDLPExecutable,
-		FFMPEGExecutable: config.FFMPEGExecutable,
-		DCAExecutable:    config.DCAExecutable,
-		Concurrency:      config.YTDLPConcurrency,
-		CacheDirectory:   config.YTDLPCacheDirectory,
+		YTDLPExecutable:   config.YTDLPExecutable,
+		FFMPEGExecutable:  config.FFMPEGExecutable,
+		DCAExecutable:     config.DCAExecutable,
+		Concurrency:       config.YTDLPConcurrency,
+		StreamConcurrency: config.StreamConcurrency,
+		CacheDirectory:    config.YTDLPCacheDirectory,
 	}
 
 	if err := cli.Listen(ctx); err != nil {
@@ -72,6 +78,7 @@ func main() {
 		AppID:         config.DiscordAppID,
 		BotToken:      config.DiscordBotToken,
 		MessagePrefix: config.DiscordMessagePrefix,
+		DJRole:        config.DiscordDJRole,
 	}
 
 	bot, err := discord.NewBot(botOpts)
@@ -79,12 +86,64 @@ func main() {
 		log.Fatal().Err(err).Send()
 	}
 
+	playerOpts := player.ManagerOptions{
+		Session:          bot.Session,
+		CLI:              &cli,
+		ObjectStore:      objectStore,
+		AloneTimeout:     config.PlayerAloneTimeout,
+		IdleTimeout:      config.PlayerIdleTimeout,
+		VoteSkipRatio:    config.PlayerVoteSkip,
+		SnapshotInterval: config.PlayerSnapshot,
+		SnapshotMaxAge:   config.PlayerSnapshotAge,
+		ClipDucking:      config.SoundboardDucking,
+		Recommender:      &player.YouTubeRecommender{YouTube: yt},
+		Sources: []player.Source{
+			&source.Attachment{ObjectStore: objectStore},
+			&source.HTTP{},
+			&source.YouTube{YouTube: yt, CLI: &cli},
+			&source.YTDLP{CLI: &cli},
+		},
+	}
+
+	if config.SponsorBlockURL != "" {
+		playerOpts.Segments = &sponsorblock.Client{BaseURL: config.SponsorBlockURL}
+	}
+
+	// players outlive the interrupt so their state can be saved during cleanup
+	players := player.NewManager(context.WithoutCancel(ctx), playerOpts)
+
+	sounds := &soundboard.Soundboard{
+		ObjectStore: objectStore,
+		CLI:         &cli,
+		Players:     players,
+		MaxDuration: config.SoundboardMaxDuration,
+	}
+
+	bot.AddVoiceListenersHandler(players.OnVoiceListeners)
+	bot.AddVoiceReconnectHandler(players.OnVoiceReconnect)
+
 	bot.RegisterCommand(ctx, cmds.Shutdown{Shutdown: shutdown})
 	bot.RegisterCommand(ctx, cmds.Beep{})
 	bot.RegisterCommand(ctx, cmds.Join{})
-	bot.RegisterCommand(ctx, cmds.Leave{})
-	bot.RegisterCommand(ctx, cmds.Play{YouTube: yt, CLI: &cli, ObjectStore: objectStore})
-	bot.RegisterCommand(ctx, cmds.Search{YouTube: yt})
+	bot.RegisterCommand(ctx, cmds.Leave{Players: players})
+	bot.RegisterCommand(ctx, cmds.Play{YouTube: yt, Players: players, PlaylistLimit: config.YouTubePlaylistLimit})
+	bot.RegisterCommand(ctx, cmds.Search{YouTube: yt, Players: players})
+	bot.RegisterCommand(ctx, cmds.Playlist{Players: players, Playlists: &playlists.Store{ObjectStore: objectStore}})
+	bot.RegisterCommand(ctx, cmds.Skip{Players: players})
+	bot.RegisterCommand(ctx, cmds.Stop{Players: players})
+	bot.RegisterCommand(ctx, cmds.Pause{Players: players})
+	bot.RegisterCommand(ctx, cmds.Resume{Players: players})
+	bot.RegisterCommand(ctx, cmds.Seek{Players: players})
+	bot.RegisterCommand(ctx, cmds.Chapter{Players: players})
+	bot.RegisterCommand(ctx, cmds.Segments{Players: players})
+	bot.RegisterCommand(ctx, cmds.Volume{Players: players})
+	bot.RegisterCommand(ctx, cmds.Filter{Players: players})
+	bot.RegisterCommand(ctx, cmds.Loop{Players: players})
+	bot.RegisterCommand(ctx, cmds.Autoplay{Players: players})
+	bot.RegisterCommand(ctx, cmds.NowPlaying{Players: players})
+	bot.RegisterCommand(ctx, cmds.History{Players: players})
+	bot.RegisterCommand(ctx, cmds.Sound{Soundboard: sounds})
+	bot.RegisterCommand(ctx, cmds.Soundboard{Soundboard: sounds})
 	// should be last
 	bot.RegisterCommand(ctx, cmds.Help{Commands: bot.Commands})
 
@@ -96,6 +155,13 @@ func main() {
 		log.Fatal().Err(err).Send()
 	}
 
+	guildIDs := make([]string, len(bot.Session.State.Guilds))
+	for i, guild := range bot.Session.State.Guilds {
+		guildIDs[i] = guild.ID
+	}
+
+	players.RestoreSnapshots(ctx, guildIDs)
+
 	router := server.NewRouter(config.ServerAddress)
 	router.Get("/api/ping", handlers.Ping(bot))
 	router.Get("/api/health", handlers.Health(bot))
@@ -113,10 +179,10 @@ func main() {
 		exitCode = 1
 	}
 
-	cleanup(bot, &server)
+	cleanup(bot, players, &server)
 }
 
-func cleanup(bot *discord.Bot, server *http.Server) {
+func cleanup(bot *discord.Bot, players *player.Manager, server *http.Server) {
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 	ctx, cancel := context.WithCancelCause(context.Background())
@@ -125,6 +191,11 @@ func cleanup(bot *discord.Bot, server *http.Server) {
 	queue.Add(1)
 	go func() {
 		defer queue.Done()
+		// players must be saved while they are still connected
+		if err := players.Close(ctx); err != nil {
+			log.Warn().Err(fmt.Errorf("failed to save players: %s", err)).Send()
+		}
+
 		if err := bot.Close(); err != nil {
 			log.Warn().Err(fmt.Errorf("failed to shutdown bot: %s", err)).Send()
 		}
@@ -0,0 +1,48 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+var (
+	_ FrameReader = (*DCAReader)(nil)
+)
+
+// DCAReader reads the frames produced by dca, each frame is an opus packet
+// prefixed with its length as a little-endian int16
+type DCAReader struct {
+	reader *bufio.Reader
+}
+
+func NewDCAReader(r io.Reader) *DCAReader {
+	return &DCAReader{reader: bufio.NewReader(r)}
+}
+
+func (r *DCAReader) ReadFrame() ([]byte, error) {
+	var frameLength int16
+	if err := binary.Read(r.reader, binary.LittleEndian, &frameLength); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("failed to read frame length: %s", err)
+	}
+
+	if frameLength < 0 {
+		return nil, fmt.Errorf("invalid frame length: %d", frameLength)
+	}
+
+	frame := make([]byte, frameLength)
+	if _, err := io.ReadFull(r.reader, frame); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("failed to read frame: %s", err)
+	}
+
+	return frame, nil
+}
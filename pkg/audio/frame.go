@@ -0,0 +1,134 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	FrameDuration = time.Millisecond * 20 // duration of a single opus frame
+	FrameSamples  = 960                   // samples per channel in a single opus frame at 48kHz
+	SampleRate    = 48000                 // samples per second per channel
+	Channels      = 2                     // audio is always stereo
+)
+
+var (
+	ErrUnsupportedContainer     = errors.New("unsupported container")
+	ErrUnsupportedCodec         = errors.New("unsupported codec")
+	ErrUnsupportedFrameDuration = errors.New("unsupported frame duration")
+)
+
+var (
+	oggMagic  = []byte("OggS")
+	ebmlMagic = []byte{0x1A, 0x45, 0xDF, 0xA3}
+)
+
+// FrameReader yields opus frames one at a time, returning io.EOF once there
+// are no frames left
+type FrameReader interface {
+	ReadFrame() ([]byte, error)
+}
+
+// NewOpusReader detects whether the input is an ogg or webm container and
+// returns a reader for the opus packets within it, the first packet is read
+// eagerly so unsupported input is rejected before playback starts
+func NewOpusReader(r io.Reader) (FrameReader, error) {
+	reader := bufio.NewReader(r)
+	magic, err := reader.Peek(4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read container magic: %s", err)
+	}
+
+	var frames FrameReader
+	switch {
+	case bytes.Equal(magic, oggMagic):
+		frames = NewOggReader(reader)
+	case bytes.Equal(magic, ebmlMagic):
+		frames = NewWebMReader(reader)
+	default:
+		return nil, ErrUnsupportedContainer
+	}
+
+	frame, err := frames.ReadFrame()
+	if err != nil {
+		return nil, err
+	}
+
+	return &prefetchReader{frames, frame}, nil
+}
+
+type prefetchReader struct {
+	FrameReader
+	pending []byte
+}
+
+func (r *prefetchReader) ReadFrame() ([]byte, error) {
+	if r.pending != nil {
+		frame := r.pending
+		r.pending = nil
+		return frame, nil
+	}
+
+	return r.FrameReader.ReadFrame()
+}
+
+// checkFrameDuration ensures the opus packet holds exactly one 20ms frame, as
+// discord advances its timestamps by a fixed amount for each packet sent
+func checkFrameDuration(packet []byte) error {
+	if len(packet) < 1 {
+		return fmt.Errorf("%s: empty packet", ErrUnsupportedFrameDuration)
+	}
+
+	// see https://datatracker.ietf.org/doc/html/rfc6716#section-3.1
+	config := packet[0] >> 3
+	var samples int
+	switch {
+	case config < 12:
+		samples = []int{480, 960, 1920, 2880}[config%4]
+	case config < 16:
+		samples = []int{480, 960}[config%2]
+	default:
+		samples = []int{120, 240, 480, 960}[config%4]
+	}
+
+	switch packet[0] & 0x03 {
+	case 1, 2:
+		samples *= 2
+	case 3:
+		if len(packet) < 2 {
+			return fmt.Errorf("%s: truncated packet", ErrUnsupportedFrameDuration)
+		}
+
+		samples *= int(packet[1] & 0x3F)
+	}
+
+	if samples != FrameSamples {
+		return fmt.Errorf("%s: got %d samples", ErrUnsupportedFrameDuration, samples)
+	}
+
+	return nil
+}
+
+// FramesFor returns the number of whole frames that fit in the duration
+func FramesFor(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+
+	return int(d / FrameDuration)
+}
+
+// SkipFrames discards up to n frames, returning io.EOF if the reader ran out
+func SkipFrames(r FrameReader, n int) error {
+	for i := 0; i < n; i++ {
+		if _, err := r.ReadFrame(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package audio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// opus packets holding a single 20ms celt frame, the remaining bytes are
+// never decoded so they can be anything
+var (
+	testFrameA = []byte{0xFC, 0x01, 0x02}
+	testFrameB = []byte{0xFC, 0x03, 0x04, 0x05}
+)
+
+func readAllFrames(t *testing.T, reader FrameReader) ([][]byte, error) {
+	t.Helper()
+
+	var frames [][]byte
+	for {
+		frame, err := reader.ReadFrame()
+		if err != nil {
+			return frames, err
+		}
+
+		frames = append(frames, frame)
+	}
+}
+
+func assertFrames(t *testing.T, got, want [][]byte) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d frames, got %d", len(want), len(got))
+	}
+
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Fatalf("frame %d: expected %x, got %x", i, want[i], got[i])
+		}
+	}
+}
+
+func assertError(t *testing.T, err error, want string) {
+	t.Helper()
+
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected error containing %q, got %v", want, err)
+	}
+}
+
+func TestCheckFrameDuration(t *testing.T) {
+	tests := []struct {
+		name   string
+		packet []byte
+		valid  bool
+	}{
+		{name: "empty packet", packet: []byte{}},
+		{name: "silk 10ms", packet: []byte{0 << 3}},
+		{name: "silk 20ms", packet: []byte{1 << 3}, valid: true},
+		{name: "silk 40ms", packet: []byte{2 << 3}},
+		{name: "hybrid 20ms", packet: []byte{13 << 3}, valid: true},
+		{name: "hybrid 10ms", packet: []byte{12 << 3}},
+		{name: "celt 2.5ms", packet: []byte{16 << 3}},
+		{name: "celt 20ms stereo", packet: []byte{31<<3 | 0x04}, valid: true},
+		{name: "two 10ms frames", packet: []byte{0<<3 | 0x01}, valid: true},
+		{name: "two 10ms frames of different sizes", packet: []byte{30<<3 | 0x02}, valid: true},
+		{name: "two 20ms frames", packet: []byte{31<<3 | 0x01}},
+		{name: "arbitrary count of two 10ms frames", packet: []byte{30<<3 | 0x03, 0x02}, valid: true},
+		{name: "arbitrary count of four 5ms frames", packet: []byte{29<<3 | 0x03, 0x04}, valid: true},
+		{name: "arbitrary count of three 10ms frames", packet: []byte{30<<3 | 0x03, 0x03}},
+		{name: "arbitrary count truncated", packet: []byte{30<<3 | 0x03}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkFrameDuration(test.packet)
+			if test.valid && err != nil {
+				t.Fatalf("expected valid packet, got %s", err)
+			}
+
+			if !test.valid {
+				assertError(t, err, ErrUnsupportedFrameDuration.Error())
+			}
+		})
+	}
+}
+
+func TestNewOpusReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		err   string
+	}{
+		{name: "ogg", input: testOgg(testOpusBOS(1), testOpusTags(1), oggPage(0, 1, testFrameA))},
+		{name: "webm", input: testWebM(ebml(webmElementSimpleBlock, testBlock(1, 0, testFrameA)))},
+		{name: "unknown container", input: []byte("RIFF...."), err: ErrUnsupportedContainer.Error()},
+		{name: "too short", input: []byte{0x1A}, err: "failed to read container magic"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader, err := NewOpusReader(bytes.NewReader(test.input))
+			if test.err != "" {
+				assertError(t, err, test.err)
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected reader, got %s", err)
+			}
+
+			frames, err := readAllFrames(t, reader)
+			if err != io.EOF {
+				t.Fatalf("expected EOF, got %s", err)
+			}
+
+			assertFrames(t, frames, [][]byte{testFrameA})
+		})
+	}
+}
+
+func TestFramesFor(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{input: "0s", want: 0},
+		{input: "-1s", want: 0},
+		{input: "19ms", want: 0},
+		{input: "20ms", want: 1},
+		{input: "1s", want: 50},
+		{input: "1m30s", want: 4500},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			d, err := time.ParseDuration(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := FramesFor(d); got != test.want {
+				t.Fatalf("expected %d frames, got %d", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// how long the music takes to duck at either end of the overlay, an instant
+// change in volume is heard as a click
+const duckRamp = SampleRate / 100
+
+// Mix lays the overlay on top of the music, which is scaled by duck first so
+// the overlay can be heard over it, both are interleaved signed 16-bit
+// little-endian pcm and the result is as long as the overlay
+func Mix(music, overlay []byte, duck float64) []byte {
+	mixed := make([]byte, len(overlay)&^1)
+	samples := len(mixed) / 2 / Channels
+	for i := 0; i < len(mixed); i += 2 {
+		sample := float64(int16(binary.LittleEndian.Uint16(overlay[i:])))
+		if i+1 < len(music) {
+			n := i / 2 / Channels
+			ramp := math.Min(1, float64(min(n, samples-1-n))/duckRamp)
+			gain := 1 - (1-duck)*ramp
+			sample += float64(int16(binary.LittleEndian.Uint16(music[i:]))) * gain
+		}
+
+		sample = math.Max(math.MinInt16, math.Min(math.MaxInt16, sample))
+		binary.LittleEndian.PutUint16(mixed[i:], uint16(int16(sample)))
+	}
+
+	return mixed
+}
@@ -0,0 +1,75 @@
+package audio
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func testPCM(samples int, value int16) []byte {
+	pcm := make([]byte, samples*Channels*2)
+	for i := 0; i < len(pcm); i += 2 {
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(value))
+	}
+
+	return pcm
+}
+
+func pcmSample(pcm []byte, n int) int16 {
+	return int16(binary.LittleEndian.Uint16(pcm[n*Channels*2:]))
+}
+
+func TestMix(t *testing.T) {
+	samples := duckRamp * 4
+
+	tests := []struct {
+		name    string
+		music   []byte
+		overlay []byte
+		duck    float64
+		checks  map[int]int16
+	}{
+		{
+			name:    "ducks the middle",
+			music:   testPCM(samples, 1000),
+			overlay: testPCM(samples, 100),
+			duck:    0.5,
+			checks:  map[int]int16{samples / 2: 600},
+		},
+		{
+			name:    "ramps at the ends",
+			music:   testPCM(samples, 1000),
+			overlay: testPCM(samples, 0),
+			duck:    0,
+			checks:  map[int]int16{0: 1000, duckRamp / 2: 500, duckRamp: 0, samples - 1: 1000},
+		},
+		{
+			name:    "clamps",
+			music:   testPCM(samples, 30000),
+			overlay: testPCM(samples, 30000),
+			duck:    1,
+			checks:  map[int]int16{samples / 2: 32767},
+		},
+		{
+			name:    "overlay outlasts music",
+			music:   testPCM(samples/2, 1000),
+			overlay: testPCM(samples, 100),
+			duck:    0.5,
+			checks:  map[int]int16{samples - 1: 100},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mixed := Mix(test.music, test.overlay, test.duck)
+			if len(mixed) != len(test.overlay) {
+				t.Fatalf("expected %d bytes, got %d", len(test.overlay), len(mixed))
+			}
+
+			for n, expected := range test.checks {
+				if got := pcmSample(mixed, n); got != expected {
+					t.Errorf("sample %d: expected %d, got %d", n, expected, got)
+				}
+			}
+		})
+	}
+}
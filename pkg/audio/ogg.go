@@ -0,0 +1,258 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+var (
+	_ FrameReader = (*OggReader)(nil)
+)
+
+const (
+	oggHeaderSize        = 27
+	oggHeaderTypeBOS     = 0x02
+	oggMaxLacingValue    = 255
+	opusHeadMagic        = "OpusHead"
+	opusTagsMagic        = "OpusTags"
+	opusHeadMinimumSize  = 19
+	opusHeadVersionMajor = 0xF0
+)
+
+// OggReader demuxes the first opus stream found in an ogg container
+//
+// see https://datatracker.ietf.org/doc/html/rfc3533 and
+// https://datatracker.ietf.org/doc/html/rfc7845
+type OggReader struct {
+	reader   io.Reader
+	serial   uint32
+	selected bool
+	tags     bool
+	partial  []byte
+	packets  [][]byte
+}
+
+func NewOggReader(r io.Reader) *OggReader {
+	return &OggReader{reader: r}
+}
+
+func (r *OggReader) ReadFrame() ([]byte, error) {
+	for {
+		for len(r.packets) > 0 {
+			packet := r.packets[0]
+			r.packets = r.packets[1:]
+
+			// the comment header directly follows the identification header
+			if !r.tags {
+				if !bytes.HasPrefix(packet, []byte(opusTagsMagic)) {
+					return nil, fmt.Errorf("%s: expected opus tags header", ErrUnsupportedCodec)
+				}
+
+				r.tags = true
+				continue
+			}
+
+			// empty packets carry no audio, usually a dropped frame
+			if len(packet) < 1 {
+				continue
+			}
+
+			if err := checkFrameDuration(packet); err != nil {
+				return nil, err
+			}
+
+			return packet, nil
+		}
+
+		if err := r.readPage(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (r *OggReader) readPage() error {
+	header := make([]byte, oggHeaderSize)
+	if _, err := io.ReadFull(r.reader, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return io.EOF
+		}
+
+		return err
+	}
+
+	if !bytes.Equal(header[:4], oggMagic) {
+		return fmt.Errorf("invalid ogg page capture pattern")
+	}
+
+	headerType := header[5]
+	serial := binary.LittleEndian.Uint32(header[14:18])
+
+	segments := make([]byte, header[26])
+	if _, err := io.ReadFull(r.reader, segments); err != nil {
+		return fmt.Errorf("failed to read ogg segment table: %s", err)
+	}
+
+	size := 0
+	for _, lacing := range segments {
+		size += int(lacing)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.reader, data); err != nil {
+		return fmt.Errorf("failed to read ogg page: %s", err)
+	}
+
+	// the first stream to start with an identification header is selected,
+	// every stream starts before any of them carry data so there is no opus
+	// stream if none has been found by the first data page
+	if !r.selected {
+		if headerType&oggHeaderTypeBOS == 0 {
+			return fmt.Errorf("%s: no opus stream found", ErrUnsupportedCodec)
+		}
+
+		if !bytes.HasPrefix(data, []byte(opusHeadMagic)) {
+			return nil
+		}
+
+		if err := checkOpusHead(data); err != nil {
+			return err
+		}
+
+		r.serial = serial
+		r.selected = true
+		return nil
+	}
+
+	if serial != r.serial {
+		return nil
+	}
+
+	offset := 0
+	for _, lacing := range segments {
+		r.partial = append(r.partial, data[offset:offset+int(lacing)]...)
+		offset += int(lacing)
+
+		// a lacing value of 255 means the packet continues in the next segment
+		if lacing < oggMaxLacingValue {
+			r.packets = append(r.packets, r.partial)
+			r.partial = nil
+		}
+	}
+
+	return nil
+}
+
+func checkOpusHead(packet []byte) error {
+	if len(packet) < opusHeadMinimumSize {
+		return fmt.Errorf("%s: opus identification header too short", ErrUnsupportedCodec)
+	}
+
+	if version := packet[8]; version&opusHeadVersionMajor != 0 {
+		return fmt.Errorf("%s: unsupported opus version %d", ErrUnsupportedCodec, version)
+	}
+
+	if channels := packet[9]; channels < 1 || channels > 2 {
+		return fmt.Errorf("%s: unsupported channel count %d", ErrUnsupportedCodec, channels)
+	}
+
+	return nil
+}
+
+// oggCRCTable is the crc32 table for ogg pages, which unlike hash/crc32 is not
+// bit reflected
+var oggCRCTable = func() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		crc := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04C11DB7
+			} else {
+				crc <<= 1
+			}
+		}
+
+		table[i] = crc
+	}
+
+	return table
+}()
+
+// OggWriter muxes opus frames into an ogg container so ffmpeg can decode them,
+// each frame gets a page of its own
+type OggWriter struct {
+	writer   io.Writer
+	sequence uint32
+	granule  uint64
+}
+
+// NewOggWriter writes the opus headers for a stereo 48kHz stream, the decoder
+// discards the first preSkip samples, which lets frames taken from the middle
+// of a stream be preceded by a few that only prime the decoder
+func NewOggWriter(w io.Writer, preSkip int) (*OggWriter, error) {
+	writer := OggWriter{writer: w}
+
+	head := make([]byte, opusHeadMinimumSize)
+	copy(head, opusHeadMagic)
+	head[8] = 1
+	head[9] = byte(Channels)
+	binary.LittleEndian.PutUint16(head[10:12], uint16(preSkip))
+	binary.LittleEndian.PutUint32(head[12:16], uint32(SampleRate))
+	if err := writer.writePage(head, oggHeaderTypeBOS); err != nil {
+		return nil, err
+	}
+
+	vendor := "guosheng"
+	tags := make([]byte, len(opusTagsMagic)+4+len(vendor)+4)
+	copy(tags, opusTagsMagic)
+	binary.LittleEndian.PutUint32(tags[8:12], uint32(len(vendor)))
+	copy(tags[12:], vendor)
+	if err := writer.writePage(tags, 0); err != nil {
+		return nil, err
+	}
+
+	return &writer, nil
+}
+
+func (w *OggWriter) WriteFrame(frame []byte) error {
+	w.granule += FrameSamples
+	return w.writePage(frame, 0)
+}
+
+func (w *OggWriter) writePage(packet []byte, headerType byte) error {
+	// a packet that fills its last segment is terminated by an empty one
+	segments := make([]byte, len(packet)/oggMaxLacingValue+1)
+	for i := range segments[:len(segments)-1] {
+		segments[i] = oggMaxLacingValue
+	}
+
+	segments[len(segments)-1] = byte(len(packet) % oggMaxLacingValue)
+	if len(segments) > oggMaxLacingValue {
+		return fmt.Errorf("ogg packet too large: %d bytes", len(packet))
+	}
+
+	page := make([]byte, oggHeaderSize, oggHeaderSize+len(segments)+len(packet))
+	copy(page, oggMagic)
+	page[5] = headerType
+	binary.LittleEndian.PutUint64(page[6:14], w.granule)
+	binary.LittleEndian.PutUint32(page[18:22], w.sequence)
+	page[26] = byte(len(segments))
+	page = append(page, segments...)
+	page = append(page, packet...)
+
+	var crc uint32
+	for _, b := range page {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+
+	binary.LittleEndian.PutUint32(page[22:26], crc)
+	w.sequence += 1
+
+	if _, err := w.writer.Write(page); err != nil {
+		return fmt.Errorf("failed to write ogg page: %s", err)
+	}
+
+	return nil
+}
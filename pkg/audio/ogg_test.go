@@ -0,0 +1,215 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+// oggPage builds a page from whole packets, pages with packets continued
+// across them are built by passing the lacing values to oggPageLacing
+func oggPage(headerType byte, serial uint32, packets ...[]byte) []byte {
+	var segments []byte
+	var data []byte
+	for _, packet := range packets {
+		for i := 0; i < len(packet)/oggMaxLacingValue; i++ {
+			segments = append(segments, oggMaxLacingValue)
+		}
+
+		segments = append(segments, byte(len(packet)%oggMaxLacingValue))
+		data = append(data, packet...)
+	}
+
+	return oggPageLacing(headerType, serial, segments, data)
+}
+
+func oggPageLacing(headerType byte, serial uint32, segments, data []byte) []byte {
+	page := make([]byte, oggHeaderSize)
+	copy(page, oggMagic)
+	page[5] = headerType
+	binary.LittleEndian.PutUint32(page[14:18], serial)
+	page[26] = byte(len(segments))
+	page = append(page, segments...)
+	return append(page, data...)
+}
+
+func testOpusHead(channels byte) []byte {
+	head := make([]byte, opusHeadMinimumSize)
+	copy(head, opusHeadMagic)
+	head[8] = 1
+	head[9] = channels
+	return head
+}
+
+func testOpusBOS(serial uint32) []byte {
+	return oggPage(oggHeaderTypeBOS, serial, testOpusHead(2))
+}
+
+func testOpusTags(serial uint32) []byte {
+	return oggPage(0, serial, append([]byte(opusTagsMagic), make([]byte, 8)...))
+}
+
+func testOgg(pages ...[]byte) []byte {
+	return bytes.Join(pages, nil)
+}
+
+// countingReader fails the test if the reader reads past the limit
+type countingReader struct {
+	t      *testing.T
+	reader io.Reader
+	limit  int
+	read   int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += n
+	if r.read > r.limit {
+		r.t.Fatalf("read %d bytes, expected at most %d", r.read, r.limit)
+	}
+
+	return n, err
+}
+
+func TestOggReader(t *testing.T) {
+	long := append([]byte{0xFC}, bytes.Repeat([]byte{0xAA}, 299)...)
+	vorbisBOS := oggPage(oggHeaderTypeBOS, 2, []byte("\x01vorbis"))
+
+	tests := []struct {
+		name   string
+		input  []byte
+		frames [][]byte
+		err    string
+	}{
+		{
+			name:   "single stream",
+			input:  testOgg(testOpusBOS(1), testOpusTags(1), oggPage(0, 1, testFrameA, testFrameB)),
+			frames: [][]byte{testFrameA, testFrameB},
+		},
+		{
+			name:   "frames across pages",
+			input:  testOgg(testOpusBOS(1), testOpusTags(1), oggPage(0, 1, testFrameA), oggPage(0, 1, testFrameB)),
+			frames: [][]byte{testFrameA, testFrameB},
+		},
+		{
+			name: "packet continued on next page",
+			input: testOgg(
+				testOpusBOS(1),
+				testOpusTags(1),
+				oggPageLacing(0, 1, []byte{255}, long[:255]),
+				oggPageLacing(0, 1, []byte{45}, long[255:]),
+			),
+			frames: [][]byte{long},
+		},
+		{
+			name:   "packet of exactly one full segment",
+			input:  testOgg(testOpusBOS(1), testOpusTags(1), oggPage(0, 1, long[:255], testFrameA)),
+			frames: [][]byte{long[:255], testFrameA},
+		},
+		{
+			name:   "empty packets are skipped",
+			input:  testOgg(testOpusBOS(1), testOpusTags(1), oggPage(0, 1, testFrameA, []byte{}, testFrameB)),
+			frames: [][]byte{testFrameA, testFrameB},
+		},
+		{
+			name: "opus stream multiplexed with vorbis",
+			input: testOgg(
+				vorbisBOS,
+				testOpusBOS(1),
+				oggPage(0, 2, []byte("\x03vorbis")),
+				testOpusTags(1),
+				oggPage(0, 2, []byte("vorbis audio")),
+				oggPage(0, 1, testFrameA),
+			),
+			frames: [][]byte{testFrameA},
+		},
+		{
+			name:  "vorbis only",
+			input: testOgg(vorbisBOS, oggPage(0, 2, []byte("\x03vorbis")), oggPage(0, 2, []byte("vorbis audio"))),
+			err:   ErrUnsupportedCodec.Error(),
+		},
+		{
+			name:  "missing tags",
+			input: testOgg(testOpusBOS(1), oggPage(0, 1, testFrameA)),
+			err:   "expected opus tags header",
+		},
+		{
+			name:  "unsupported channel count",
+			input: testOgg(oggPage(oggHeaderTypeBOS, 1, testOpusHead(3))),
+			err:   "unsupported channel count",
+		},
+		{
+			name:  "unsupported frame duration",
+			input: testOgg(testOpusBOS(1), testOpusTags(1), oggPage(0, 1, []byte{30 << 3})),
+			err:   ErrUnsupportedFrameDuration.Error(),
+		},
+		{
+			name:  "invalid capture pattern",
+			input: testOgg(testOpusBOS(1), []byte("OggX"), make([]byte, oggHeaderSize)),
+			err:   "invalid ogg page capture pattern",
+		},
+		{
+			name:  "truncated page",
+			input: testOgg(testOpusBOS(1), testOpusTags(1), oggPage(0, 1, testFrameA)[:oggHeaderSize+2]),
+			err:   "failed to read ogg page",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			frames, err := readAllFrames(t, NewOggReader(bytes.NewReader(test.input)))
+			if test.err != "" {
+				assertError(t, err, test.err)
+				return
+			}
+
+			if err != io.EOF {
+				t.Fatalf("expected EOF, got %v", err)
+			}
+
+			assertFrames(t, frames, test.frames)
+		})
+	}
+}
+
+func TestOggReaderStopsAtFirstDataPageWithoutOpus(t *testing.T) {
+	vorbisBOS := oggPage(oggHeaderTypeBOS, 2, []byte("\x01vorbis"))
+	header := oggPage(0, 2, []byte("\x03vorbis"))
+	rest := bytes.Repeat(oggPage(0, 2, []byte("vorbis audio")), 100)
+
+	input := testOgg(vorbisBOS, header, rest)
+	reader := countingReader{t: t, reader: bytes.NewReader(input), limit: len(vorbisBOS) + len(header)}
+
+	_, err := NewOggReader(&reader).ReadFrame()
+	assertError(t, err, ErrUnsupportedCodec.Error())
+}
+
+func TestOggWriter(t *testing.T) {
+	long := append([]byte{0xFC}, bytes.Repeat([]byte{0xAA}, 509)...)
+	frames := [][]byte{testFrameA, long, testFrameB}
+
+	buffer := bytes.Buffer{}
+	writer, err := NewOggWriter(&buffer, 3840)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the opus head follows the page header and its single lacing value
+	if preSkip := binary.LittleEndian.Uint16(buffer.Bytes()[28+10:]); preSkip != 3840 {
+		t.Fatalf("expected pre-skip 3840, got %d", preSkip)
+	}
+
+	for _, frame := range frames {
+		if err := writer.WriteFrame(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := readAllFrames(t, NewOggReader(&buffer))
+	if err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+
+	assertFrames(t, got, frames)
+}
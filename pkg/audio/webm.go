@@ -0,0 +1,317 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+var (
+	_ FrameReader = (*WebMReader)(nil)
+)
+
+// element ids, see https://www.matroska.org/technical/elements.html
+const (
+	webmElementEBML         = 0x1A45DFA3
+	webmElementSegment      = 0x18538067
+	webmElementTracks       = 0x1654AE6B
+	webmElementTrackEntry   = 0xAE
+	webmElementTrackNumber  = 0xD7
+	webmElementCodecID      = 0x86
+	webmElementCodecPrivate = 0x63A2
+	webmElementCluster      = 0x1F43B675
+	webmElementBlockGroup   = 0xA0
+	webmElementBlock        = 0xA1
+	webmElementSimpleBlock  = 0xA3
+)
+
+const (
+	webmCodecOpus         = "A_OPUS"
+	webmUnknownSize       = -1
+	webmLacingNone        = 0
+	webmLacingXiph        = 1
+	webmLacingFixed       = 2
+	webmLacingEBML        = 3
+	webmMaxElementPayload = 1 << 24
+)
+
+type webmTrack struct {
+	number  uint64
+	codecID string
+	private []byte
+}
+
+// WebMReader demuxes the first opus track found in a webm or matroska
+// container, master elements are walked in order rather than by their size so
+// live and unfinalised files with unknown sizes are supported
+type WebMReader struct {
+	reader   *bufio.Reader
+	tracks   []*webmTrack
+	selected *webmTrack
+	frames   [][]byte
+}
+
+func NewWebMReader(r io.Reader) *WebMReader {
+	return &WebMReader{reader: bufio.NewReader(r)}
+}
+
+func (r *WebMReader) ReadFrame() ([]byte, error) {
+	for {
+		for len(r.frames) < 1 {
+			if err := r.readElement(); err != nil {
+				return nil, err
+			}
+		}
+
+		frame := r.frames[0]
+		r.frames = r.frames[1:]
+
+		// empty frames carry nothing to play
+		if len(frame) < 1 {
+			continue
+		}
+
+		if err := checkFrameDuration(frame); err != nil {
+			return nil, err
+		}
+
+		return frame, nil
+	}
+}
+
+func (r *WebMReader) readElement() error {
+	id, _, err := readVint(r.reader, true)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return io.EOF
+		}
+
+		return err
+	}
+
+	size, err := readElementSize(r.reader)
+	if err != nil {
+		return fmt.Errorf("failed to read size of element %x: %s", id, err)
+	}
+
+	switch id {
+	case webmElementSegment, webmElementTracks, webmElementCluster, webmElementBlockGroup:
+		// descend into the children of master elements
+		return nil
+
+	case webmElementTrackEntry:
+		r.tracks = append(r.tracks, &webmTrack{})
+		return nil
+	}
+
+	if size == webmUnknownSize {
+		return fmt.Errorf("element %x has unknown size", id)
+	}
+
+	switch id {
+	case webmElementTrackNumber, webmElementCodecID, webmElementCodecPrivate, webmElementBlock, webmElementSimpleBlock:
+		if size > webmMaxElementPayload {
+			return fmt.Errorf("element %x exceeds maximum size: %d", id, size)
+		}
+
+	default:
+		if _, err := r.reader.Discard(int(size)); err != nil {
+			return fmt.Errorf("failed to skip element %x: %s", id, err)
+		}
+
+		return nil
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r.reader, payload); err != nil {
+		return fmt.Errorf("failed to read element %x: %s", id, err)
+	}
+
+	if id == webmElementBlock || id == webmElementSimpleBlock {
+		return r.readBlock(payload)
+	}
+
+	if len(r.tracks) < 1 {
+		return nil
+	}
+
+	track := r.tracks[len(r.tracks)-1]
+	switch id {
+	case webmElementTrackNumber:
+		for _, b := range payload {
+			track.number = track.number<<8 | uint64(b)
+		}
+	case webmElementCodecID:
+		track.codecID = string(bytes.TrimRight(payload, "\x00"))
+	case webmElementCodecPrivate:
+		track.private = payload
+	}
+
+	return nil
+}
+
+func (r *WebMReader) selectTrack() error {
+	for _, track := range r.tracks {
+		if track.codecID != webmCodecOpus {
+			continue
+		}
+
+		if track.private != nil {
+			if err := checkOpusHead(track.private); err != nil {
+				return err
+			}
+		}
+
+		r.selected = track
+		return nil
+	}
+
+	return fmt.Errorf("%s: no opus track found", ErrUnsupportedCodec)
+}
+
+func (r *WebMReader) readBlock(payload []byte) error {
+	if r.selected == nil {
+		if err := r.selectTrack(); err != nil {
+			return err
+		}
+	}
+
+	block := bytes.NewReader(payload)
+	number, _, err := readVint(block, false)
+	if err != nil {
+		return fmt.Errorf("failed to read block track number: %s", err)
+	}
+
+	if number != r.selected.number {
+		return nil
+	}
+
+	// skip the relative timecode
+	header := make([]byte, 3)
+	if _, err := io.ReadFull(block, header); err != nil {
+		return fmt.Errorf("failed to read block header: %s", err)
+	}
+
+	lacing := (header[2] >> 1) & 0x03
+	if lacing == webmLacingNone {
+		r.frames = append(r.frames, payload[len(payload)-block.Len():])
+		return nil
+	}
+
+	count, err := block.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read lace count: %s", err)
+	}
+
+	sizes := make([]int, int(count)+1)
+	switch lacing {
+	case webmLacingXiph:
+		for i := 0; i < len(sizes)-1; i++ {
+			for {
+				b, err := block.ReadByte()
+				if err != nil {
+					return fmt.Errorf("failed to read xiph lace size: %s", err)
+				}
+
+				sizes[i] += int(b)
+				if b < 0xFF {
+					break
+				}
+			}
+		}
+
+	case webmLacingEBML:
+		first, _, err := readVint(block, false)
+		if err != nil {
+			return fmt.Errorf("failed to read ebml lace size: %s", err)
+		}
+
+		sizes[0] = int(first)
+		for i := 1; i < len(sizes)-1; i++ {
+			raw, length, err := readVint(block, false)
+			if err != nil {
+				return fmt.Errorf("failed to read ebml lace size: %s", err)
+			}
+
+			// lace sizes after the first are signed differences
+			sizes[i] = sizes[i-1] + int(raw) - (1<<(7*length-1) - 1)
+		}
+
+	case webmLacingFixed:
+		for i := range sizes {
+			sizes[i] = block.Len() / len(sizes)
+		}
+	}
+
+	if lacing != webmLacingFixed {
+		sizes[len(sizes)-1] = block.Len()
+		for _, size := range sizes[:len(sizes)-1] {
+			sizes[len(sizes)-1] -= size
+		}
+	}
+
+	data := payload[len(payload)-block.Len():]
+	for _, size := range sizes {
+		if size < 0 || size > len(data) {
+			return fmt.Errorf("invalid lace size %d", size)
+		}
+
+		r.frames = append(r.frames, data[:size])
+		data = data[size:]
+	}
+
+	return nil
+}
+
+// readVint reads an ebml variable length integer, element ids keep their
+// length marker while sizes and track numbers do not
+func readVint(r io.ByteReader, keepMarker bool) (uint64, int, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			return 0, 0, io.ErrUnexpectedEOF
+		}
+
+		return 0, 0, err
+	}
+
+	length := 1
+	for mask := byte(0x80); first&mask == 0; mask >>= 1 {
+		if mask == 0x01 {
+			return 0, 0, fmt.Errorf("invalid variable length integer")
+		}
+
+		length += 1
+	}
+
+	value := uint64(first)
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+
+	for i := 1; i < length; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to read variable length integer: %s", err)
+		}
+
+		value = value<<8 | uint64(b)
+	}
+
+	return value, length, nil
+}
+
+func readElementSize(r io.ByteReader) (int64, error) {
+	size, length, err := readVint(r, false)
+	if err != nil {
+		return 0, err
+	}
+
+	// all value bits set indicates an unknown size
+	if size == 1<<(7*length)-1 {
+		return webmUnknownSize, nil
+	}
+
+	return int64(size), nil
+}
@@ -0,0 +1,215 @@
+package audio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// ebml builds an element with a known size
+func ebml(id uint64, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	return append(append(ebmlID(id), ebmlSize(uint64(len(data)))...), data...)
+}
+
+// ebmlUnknown builds a master element with an unknown size
+func ebmlUnknown(id uint64, payload ...[]byte) []byte {
+	header := append(ebmlID(id), 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)
+	return append(header, bytes.Join(payload, nil)...)
+}
+
+func ebmlID(id uint64) []byte {
+	var encoded []byte
+	for ; id > 0; id >>= 8 {
+		encoded = append([]byte{byte(id)}, encoded...)
+	}
+
+	return encoded
+}
+
+func ebmlSize(size uint64) []byte {
+	switch {
+	case size < 0x7F:
+		return []byte{0x80 | byte(size)}
+	case size < 0x3FFF:
+		return []byte{0x40 | byte(size>>8), byte(size)}
+	default:
+		return []byte{0x10 | byte(size>>24), byte(size >> 16), byte(size >> 8), byte(size)}
+	}
+}
+
+func testTrack(number byte, codecID string) []byte {
+	return ebml(webmElementTrackEntry,
+		ebml(webmElementTrackNumber, []byte{number}),
+		ebml(webmElementCodecID, []byte(codecID)),
+	)
+}
+
+// testBlock builds the payload of a block, flags set the lacing
+func testBlock(track byte, flags byte, data ...[]byte) []byte {
+	block := []byte{0x80 | track, 0x00, 0x00, flags}
+	return append(block, bytes.Join(data, nil)...)
+}
+
+func testWebM(clusters ...[]byte) []byte {
+	return append(
+		ebml(webmElementEBML, ebml(0x4282, []byte("webm"))),
+		ebml(webmElementSegment,
+			ebml(webmElementTracks, testTrack(1, webmCodecOpus)),
+			ebml(webmElementCluster, clusters...),
+		)...,
+	)
+}
+
+func TestWebMReader(t *testing.T) {
+	frameC := []byte{0xFC, 0x06}
+
+	tests := []struct {
+		name   string
+		input  []byte
+		frames [][]byte
+		err    string
+	}{
+		{
+			name: "simple blocks",
+			input: testWebM(
+				ebml(webmElementSimpleBlock, testBlock(1, 0, testFrameA)),
+				ebml(webmElementSimpleBlock, testBlock(1, 0, testFrameB)),
+			),
+			frames: [][]byte{testFrameA, testFrameB},
+		},
+		{
+			name:   "block groups",
+			input:  testWebM(ebml(webmElementBlockGroup, ebml(webmElementBlock, testBlock(1, 0, testFrameA)))),
+			frames: [][]byte{testFrameA},
+		},
+		{
+			name: "unknown sizes",
+			input: append(
+				ebml(webmElementEBML),
+				ebmlUnknown(webmElementSegment,
+					ebml(webmElementTracks, testTrack(1, webmCodecOpus)),
+					ebmlUnknown(webmElementCluster, ebml(webmElementSimpleBlock, testBlock(1, 0, testFrameA))),
+					ebmlUnknown(webmElementCluster, ebml(webmElementSimpleBlock, testBlock(1, 0, testFrameB))),
+				)...,
+			),
+			frames: [][]byte{testFrameA, testFrameB},
+		},
+		{
+			name: "opus is not the first track",
+			input: append(
+				ebml(webmElementEBML),
+				ebml(webmElementSegment,
+					ebml(webmElementTracks, testTrack(1, "V_VP9"), testTrack(2, webmCodecOpus)),
+					ebml(webmElementCluster,
+						ebml(webmElementSimpleBlock, testBlock(1, 0, []byte("video"))),
+						ebml(webmElementSimpleBlock, testBlock(2, 0, testFrameA)),
+					),
+				)...,
+			),
+			frames: [][]byte{testFrameA},
+		},
+		{
+			name:   "xiph lacing",
+			input:  testWebM(ebml(webmElementSimpleBlock, testBlock(1, 0x02, []byte{2, 3, 4}, testFrameA, testFrameB, frameC))),
+			frames: [][]byte{testFrameA, testFrameB, frameC},
+		},
+		{
+			name:   "fixed lacing",
+			input:  testWebM(ebml(webmElementSimpleBlock, testBlock(1, 0x04, []byte{1}, testFrameA, []byte{0xFC, 0x09, 0x09}))),
+			frames: [][]byte{testFrameA, {0xFC, 0x09, 0x09}},
+		},
+		{
+			// sizes 3 then 4, the second is stored as a difference of +1
+			name:   "ebml lacing",
+			input:  testWebM(ebml(webmElementSimpleBlock, testBlock(1, 0x06, []byte{2, 0x83, 0x80 | 64}, testFrameA, testFrameB, frameC))),
+			frames: [][]byte{testFrameA, testFrameB, frameC},
+		},
+		{
+			name: "empty frames are skipped",
+			input: testWebM(
+				ebml(webmElementSimpleBlock, testBlock(1, 0)),
+				ebml(webmElementSimpleBlock, testBlock(1, 0x02, []byte{2, 3, 0}, testFrameA, []byte{}, testFrameB)),
+			),
+			frames: [][]byte{testFrameA, testFrameB},
+		},
+		{
+			name: "no opus track",
+			input: append(
+				ebml(webmElementEBML),
+				ebml(webmElementSegment,
+					ebml(webmElementTracks, testTrack(1, "A_VORBIS")),
+					ebml(webmElementCluster, ebml(webmElementSimpleBlock, testBlock(1, 0, []byte("vorbis")))),
+				)...,
+			),
+			err: ErrUnsupportedCodec.Error(),
+		},
+		{
+			name:  "invalid lace size",
+			input: testWebM(ebml(webmElementSimpleBlock, testBlock(1, 0x02, []byte{1, 200}, testFrameA))),
+			err:   "invalid lace size",
+		},
+		{
+			name:  "unsupported frame duration",
+			input: testWebM(ebml(webmElementSimpleBlock, testBlock(1, 0, []byte{30 << 3}))),
+			err:   ErrUnsupportedFrameDuration.Error(),
+		},
+		{
+			name:  "unknown size leaf element",
+			input: testWebM(ebmlUnknown(webmElementSimpleBlock)),
+			err:   "unknown size",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			frames, err := readAllFrames(t, NewWebMReader(bytes.NewReader(test.input)))
+			if test.err != "" {
+				assertError(t, err, test.err)
+				return
+			}
+
+			if err != io.EOF {
+				t.Fatalf("expected EOF, got %v", err)
+			}
+
+			assertFrames(t, frames, test.frames)
+		})
+	}
+}
+
+func TestReadVint(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      []byte
+		keepMarker bool
+		value      uint64
+		length     int
+		err        bool
+	}{
+		{name: "one byte", input: []byte{0x81}, value: 1, length: 1},
+		{name: "one byte with marker", input: []byte{0xA3}, keepMarker: true, value: 0xA3, length: 1},
+		{name: "two bytes", input: []byte{0x40, 0x02}, value: 2, length: 2},
+		{name: "four byte id", input: []byte{0x1A, 0x45, 0xDF, 0xA3}, keepMarker: true, value: webmElementEBML, length: 4},
+		{name: "invalid", input: []byte{0x00}, err: true},
+		{name: "truncated", input: []byte{0x40}, err: true},
+		{name: "empty", input: []byte{}, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, length, err := readVint(bytes.NewReader(test.input), test.keepMarker)
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected error, got %d", value)
+				}
+
+				return
+			}
+
+			if err != nil || value != test.value || length != test.length {
+				t.Fatalf("expected %x (%d bytes), got %x (%d bytes): %v", test.value, test.length, value, length, err)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 )
 
 var (
@@ -32,7 +33,11 @@ type ObjectStore interface {
 	Type() ObjectStoreType
 	Get(context.Context, string) ([]byte, error)
 	Put(context.Context, string, []byte, map[string]string) (*ObjectInfo, error)
+	GetStream(context.Context, string) (io.ReadCloser, error)
+	PutStream(context.Context, string, io.Reader, map[string]string) (*ObjectInfo, error)
 	Stat(context.Context, string) (*ObjectInfo, error)
+	// Delete removes the object, deleting a missing object is not an error
+	Delete(context.Context, string) error
 }
 
 type ObjectStoreOptions struct {
@@ -1,9 +1,11 @@
 package cache
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path"
 )
@@ -43,8 +45,49 @@ func (c *FilesystemClient) Get(ctx context.Context, key string) ([]byte, error)
 }
 
 func (c *FilesystemClient) Put(ctx context.Context, key string, raw []byte, tags map[string]string) (*ObjectInfo, error) {
+	return c.PutStream(ctx, key, bytes.NewReader(raw), tags)
+}
+
+func (c *FilesystemClient) GetStream(ctx context.Context, key string) (io.ReadCloser, error) {
+	filename := path.Join(c.baseDir, key)
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrObjectNotFound
+		}
+
+		return nil, fmt.Errorf("failed to open file %s: %s", filename, err)
+	}
+
+	return file, nil
+}
+
+// PutStream writes to a temporary file which is only moved into place once the
+// reader is exhausted, so an interrupted stream never leaves a partial object
+func (c *FilesystemClient) PutStream(ctx context.Context, key string, reader io.Reader, tags map[string]string) (*ObjectInfo, error) {
 	filename := path.Join(c.baseDir, key)
-	if err := os.WriteFile(filename, raw, 0666); err != nil {
+	if err := os.MkdirAll(path.Dir(filename), 0777); err != nil {
+		return nil, fmt.Errorf("failed to create directory for file %s: %s", filename, err)
+	}
+
+	file, err := os.CreateTemp(path.Dir(filename), fmt.Sprintf("%s.*.tmp", path.Base(filename)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file for %s: %s", filename, err)
+	}
+
+	defer os.Remove(file.Name())
+
+	size, err := io.Copy(file, reader)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write file %s: %s", filename, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write file %s: %s", filename, err)
+	}
+
+	if err := os.Rename(file.Name(), filename); err != nil {
 		return nil, fmt.Errorf("failed to write file %s: %s", filename, err)
 	}
 
@@ -63,7 +106,7 @@ func (c *FilesystemClient) Put(ctx context.Context, key string, raw []byte, tags
 	info := ObjectInfo{
 		Key:  key,
 		ETag: key,
-		Size: int64(len(raw)),
+		Size: size,
 		Tags: tags,
 	}
 
@@ -103,3 +146,14 @@ func (c *FilesystemClient) Stat(ctx context.Context, key string) (*ObjectInfo, e
 
 	return &info, nil
 }
+
+func (c *FilesystemClient) Delete(ctx context.Context, key string) error {
+	filename := path.Join(c.baseDir, key)
+	for _, name := range []string{filename, fmt.Sprintf("%s.tags.json", filename)} {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete file %s: %s", name, err)
+		}
+	}
+
+	return nil
+}
@@ -85,6 +85,36 @@ func (c *MinioClient) Put(ctx context.Context, key string, raw []byte, tags map[
 	return &info, nil
 }
 
+func (c *MinioClient) GetStream(ctx context.Context, key string) (io.ReadCloser, error) {
+	// errors from GetObject are deferred until the first read so check first
+	if _, err := c.Stat(ctx, key); err != nil {
+		return nil, err
+	}
+
+	object, err := c.client.GetObject(ctx, c.bucketName, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %s", key, err)
+	}
+
+	return object, nil
+}
+
+func (c *MinioClient) PutStream(ctx context.Context, key string, reader io.Reader, tags map[string]string) (*ObjectInfo, error) {
+	upload, err := c.client.PutObject(ctx, c.bucketName, key, reader, -1, minio.PutObjectOptions{UserTags: tags})
+	if err != nil {
+		return nil, fmt.Errorf("failed to put object %s: %s", key, err)
+	}
+
+	info := ObjectInfo{
+		Key:  upload.Key,
+		ETag: upload.ETag,
+		Size: upload.Size,
+		Tags: tags,
+	}
+
+	return &info, nil
+}
+
 func (c *MinioClient) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
 	stat, err := c.client.StatObject(ctx, c.bucketName, key, minio.GetObjectOptions{})
 	if err != nil {
@@ -104,3 +134,11 @@ func (c *MinioClient) Stat(ctx context.Context, key string) (*ObjectInfo, error)
 
 	return &info, nil
 }
+
+func (c *MinioClient) Delete(ctx context.Context, key string) error {
+	if err := c.client.RemoveObject(ctx, c.bucketName, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete object %s: %s", key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// EncodeClip is like Encode but keeps only the audio between start and end,
+// an end of 0 keeps everything after start
+func (e *Executor) EncodeClip(ctx context.Context, id string, in io.Reader, start, end time.Duration, filters ...string) (io.ReadCloser, error) {
+	build := func(ctx context.Context) []*exec.Cmd {
+		input := []string{"-i", "pipe:0", "-ss", fmt.Sprintf("%.3f", start.Seconds())}
+		if end > start {
+			input = append(input, "-t", fmt.Sprintf("%.3f", (end-start).Seconds()))
+		}
+
+		return e.encodeCommands(ctx, input, filters)
+	}
+
+	stream, err := e.startStream(ctx, in, build)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode clip %s: %s", id, err)
+	}
+
+	return stream, nil
+}
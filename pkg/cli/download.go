@@ -3,16 +3,22 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
-	"strings"
-
-	"github.com/rs/zerolog/log"
 )
 
-func (e *Executor) Download(id string) (buffer []byte, err error) {
-	job := func(ctx context.Context) (err error) {
-		cmd := exec.CommandContext(ctx, e.YTDLPExecutable,
-			fmt.Sprintf("https://youtube.com/watch?v=%s", id),
+// Download streams the audio of the youtube video to the returned reader, which
+// must be closed to release the yt-dlp process
+func (e *Executor) Download(ctx context.Context, id string) (io.ReadCloser, error) {
+	return e.DownloadURL(ctx, fmt.Sprintf("https://youtube.com/watch?v=%s", id))
+}
+
+// DownloadURL streams the audio of any page supported by yt-dlp to the
+// returned reader, which must be closed to release the yt-dlp process
+func (e *Executor) DownloadURL(ctx context.Context, url string) (io.ReadCloser, error) {
+	build := func(ctx context.Context) []*exec.Cmd {
+		return []*exec.Cmd{exec.CommandContext(ctx, e.YTDLPExecutable,
+			url,
 			"--cache-dir", e.CacheDirectory,
 			"--abort-on-error",
 			"--no-mark-watched",
@@ -27,22 +33,13 @@ func (e *Executor) Download(id string) (buffer []byte, err error) {
 			"--quiet",
 			"--no-simulate",
 			"--output", "-",
-		)
-
-		if buffer, err = cmd.Output(); err != nil {
-			if err, ok := err.(*exec.ExitError); ok && len(err.Stderr) > 0 {
-				log.Warn().Bytes("stderr", err.Stderr).Msg("stderr was not empty")
-			}
-
-			return fmt.Errorf("failed to execute '%s %s': %s", e.YTDLPExecutable, strings.Join(cmd.Args, " "), err)
-		}
-
-		return nil
+		)}
 	}
 
-	if err = e.execute(fmt.Sprintf("download:%s", id), job); err != nil {
-		return nil, fmt.Errorf("failed to download %s: %s", id, err)
+	stream, err := e.startStream(ctx, nil, build)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %s", url, err)
 	}
 
-	return buffer, nil
+	return stream, nil
 }
@@ -1,12 +1,12 @@
 package cli
 
 import (
-	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
-
-	"github.com/axatol/go-utils/executil"
+	"strings"
+	"time"
 )
 
 // from https://github.com/bwmarrin/dgvoice/blob/master/dgvoice.go
@@ -18,43 +18,115 @@ var (
 	OpusMaxBytes    = (OpusFrameSize * 2) * 2 // max size of opus data
 )
 
-func (e *Executor) Encode(id string, in []byte) (out []byte, err error) {
-	job := func(ctx context.Context) (err error) {
-		ctx, cancel := context.WithCancelCause(ctx)
+// Encode streams the input through ffmpeg and dca, the returned reader yields
+// dca frames as soon as they are produced and must be closed to release the
+// processes, filters are applied in order as an ffmpeg audio filter graph
+//
+// the output starts from the start position of the input, ffmpeg decodes and
+// discards everything before it which is much quicker than encoding it
+func (e *Executor) Encode(ctx context.Context, id string, in io.Reader, start time.Duration, filters ...string) (io.ReadCloser, error) {
+	build := func(ctx context.Context) []*exec.Cmd {
+		return e.encodeCommands(ctx, append(seekArgs(start), "-i", "pipe:0"), filters)
+	}
 
-		ffmpeg := exec.CommandContext(ctx, e.FFMPEGExecutable,
-			"-i", "pipe:0",
-			"-f", FFMPEGFormatPCM,
-			"-ar", fmt.Sprint(OpusFrameRate),
-			"-ac", fmt.Sprint(OpusChannels),
-			"pipe:1",
-		)
+	stream, err := e.startStream(ctx, in, build)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %s", id, err)
+	}
 
-		ffmpeg.Stdin = bytes.NewReader(in)
+	return stream, nil
+}
 
-		dca := exec.CommandContext(ctx, e.DCAExecutable,
-			"-aa", "audio",
-			"-ac", fmt.Sprint(OpusChannels),
-			"-ar", fmt.Sprint(OpusFrameRate),
-			"-as", fmt.Sprint(OpusFrameSize),
+// EncodeURL is like Encode but has ffmpeg read the url itself, which lets it
+// seek with range requests rather than reading everything before start
+func (e *Executor) EncodeURL(ctx context.Context, id string, url string, start time.Duration, filters ...string) (io.ReadCloser, error) {
+	build := func(ctx context.Context) []*exec.Cmd {
+		input := append(seekArgs(start),
+			"-reconnect", "1",
+			"-reconnect_delay_max", "5",
+			"-i", url,
 		)
 
-		pipeline, err := executil.NewPipeline(ffmpeg, dca)
-		if err != nil {
-			return fmt.Errorf("failed to create pipeline: %s", err)
-		}
+		return e.encodeCommands(ctx, input, filters)
+	}
+
+	stream, err := e.startStream(ctx, nil, build)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %s", id, err)
+	}
 
-		if out, err = pipeline.Execute(); err != nil {
-			cancel(err)
-			return fmt.Errorf("failed to execute pipeline: %s", err)
-		}
+	return stream, nil
+}
 
+func seekArgs(start time.Duration) []string {
+	if start <= 0 {
 		return nil
 	}
 
-	if err = e.execute(fmt.Sprintf("encode:%s", id), job); err != nil {
-		return nil, fmt.Errorf("failed to encode %s: %s", id, err)
+	return []string{"-ss", fmt.Sprintf("%.3f", start.Seconds())}
+}
+
+// encodeCommands builds the ffmpeg and dca pipeline reading from the given
+// ffmpeg input arguments
+func (e *Executor) encodeCommands(ctx context.Context, input []string, filters []string) []*exec.Cmd {
+	args := append([]string{}, input...)
+	if len(filters) > 0 {
+		args = append(args, "-af", strings.Join(filters, ","))
+	}
+
+	args = append(args,
+		"-f", FFMPEGFormatPCM,
+		"-ar", fmt.Sprint(OpusFrameRate),
+		"-ac", fmt.Sprint(OpusChannels),
+		"pipe:1",
+	)
+
+	ffmpeg := exec.CommandContext(ctx, e.FFMPEGExecutable, args...)
+
+	return []*exec.Cmd{ffmpeg, e.dcaCommand(ctx)}
+}
+
+// dcaCommand encodes pcm from stdin into dca frames
+func (e *Executor) dcaCommand(ctx context.Context) *exec.Cmd {
+	return exec.CommandContext(ctx, e.DCAExecutable,
+		"-aa", "audio",
+		"-ac", fmt.Sprint(OpusChannels),
+		"-ar", fmt.Sprint(OpusFrameRate),
+		"-as", fmt.Sprint(OpusFrameSize),
+	)
+}
+
+// EncodePCM encodes pcm in the format produced by Decode into dca frames
+func (e *Executor) EncodePCM(ctx context.Context, id string, in io.Reader) (io.ReadCloser, error) {
+	build := func(ctx context.Context) []*exec.Cmd {
+		return []*exec.Cmd{e.dcaCommand(ctx)}
+	}
+
+	stream, err := e.startStream(ctx, in, build)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pcm %s: %s", id, err)
+	}
+
+	return stream, nil
+}
+
+// Decode streams the input through ffmpeg into signed 16-bit little-endian
+// stereo pcm at the opus sample rate
+func (e *Executor) Decode(ctx context.Context, id string, in io.Reader) (io.ReadCloser, error) {
+	build := func(ctx context.Context) []*exec.Cmd {
+		return []*exec.Cmd{exec.CommandContext(ctx, e.FFMPEGExecutable,
+			"-i", "pipe:0",
+			"-f", FFMPEGFormatPCM,
+			"-ar", fmt.Sprint(OpusFrameRate),
+			"-ac", fmt.Sprint(OpusChannels),
+			"pipe:1",
+		)}
+	}
+
+	stream, err := e.startStream(ctx, in, build)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %s", id, err)
 	}
 
-	return out, nil
+	return stream, nil
 }
@@ -13,9 +13,14 @@ type Executor struct {
 	YTDLPExecutable  string
 	FFMPEGExecutable string
 	DCAExecutable    string
-	Concurrency      int
-	CacheDirectory   string
-	queue            *util.Queue
+	// how many yt-dlp lookups run at once
+	Concurrency int
+	// how many streams of processes run at once, 0 for no limit, every
+	// playing guild has one and mixing a clip briefly takes three more
+	StreamConcurrency int
+	CacheDirectory    string
+	queue             *util.Queue
+	streams           chan struct{}
 }
 
 func (e *Executor) Listen(ctx context.Context) error {
@@ -39,6 +44,10 @@ func (e *Executor) Listen(ctx context.Context) error {
 		return fmt.Errorf("concurrency must be greater than 0, got: %d", e.Concurrency)
 	}
 
+	if e.StreamConcurrency > 0 && e.streams == nil {
+		e.streams = make(chan struct{}, e.StreamConcurrency)
+	}
+
 	if _, err := os.Stat(e.CacheDirectory); err != nil {
 		return fmt.Errorf("could not stat cache directory %s: %s", e.CacheDirectory, err)
 	}
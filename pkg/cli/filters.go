@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FilterPreset is a named ffmpeg audio filter, optionally parameterised by a
+// single value such as a gain or a speed multiplier
+type FilterPreset struct {
+	Name         string
+	Description  string
+	DefaultValue float64
+	MinValue     float64
+	MaxValue     float64
+	// the value is the factor the preset speeds playback up by
+	tempo bool
+	build func(value float64) string
+}
+
+var (
+	filterPresets = map[string]FilterPreset{}
+)
+
+func init() {
+	RegisterFilterPreset(FilterPreset{
+		Name:         "bassboost",
+		Description:  "boost the bass by the given gain in dB",
+		DefaultValue: 10,
+		MinValue:     1,
+		MaxValue:     30,
+		build: func(value float64) string {
+			return fmt.Sprintf("bass=g=%g", value)
+		},
+	})
+
+	RegisterFilterPreset(FilterPreset{
+		Name:         "nightcore",
+		Description:  "raise the pitch and speed by the given factor",
+		DefaultValue: 1.25,
+		MinValue:     1,
+		MaxValue:     2,
+		tempo:        true,
+		build: func(value float64) string {
+			return resampleFilter(value)
+		},
+	})
+
+	RegisterFilterPreset(FilterPreset{
+		Name:         "vaporwave",
+		Description:  "lower the pitch and speed by the given factor",
+		DefaultValue: 0.8,
+		MinValue:     0.5,
+		MaxValue:     1,
+		tempo:        true,
+		build: func(value float64) string {
+			return resampleFilter(value)
+		},
+	})
+
+	RegisterFilterPreset(FilterPreset{
+		Name:         "speed",
+		Description:  "change the speed by the given factor without changing the pitch",
+		DefaultValue: 1.5,
+		MinValue:     0.5,
+		MaxValue:     4,
+		tempo:        true,
+		build: func(value float64) string {
+			// atempo only accepts values up to 2 in older versions of ffmpeg
+			var filters []string
+			for ; value > 2; value /= 2 {
+				filters = append(filters, "atempo=2")
+			}
+
+			return strings.Join(append(filters, fmt.Sprintf("atempo=%g", value)), ",")
+		},
+	})
+
+	RegisterFilterPreset(FilterPreset{
+		Name:        "karaoke",
+		Description: "remove centre panned vocals",
+		build: func(value float64) string {
+			return "pan=stereo|c0=c0-c1|c1=c1-c0"
+		},
+	})
+
+	RegisterFilterPreset(FilterPreset{
+		Name:         "8d",
+		Description:  "pan the audio around the listener at the given rate in Hz",
+		DefaultValue: 0.125,
+		MinValue:     0.01,
+		MaxValue:     2,
+		build: func(value float64) string {
+			return fmt.Sprintf("apulsator=hz=%g", value)
+		},
+	})
+}
+
+func resampleFilter(factor float64) string {
+	return fmt.Sprintf("aresample=%d,asetrate=%d*%g,aresample=%d", OpusFrameRate, OpusFrameRate, factor, OpusFrameRate)
+}
+
+func RegisterFilterPreset(preset FilterPreset) {
+	filterPresets[preset.Name] = preset
+}
+
+func GetFilterPreset(name string) (FilterPreset, bool) {
+	preset, ok := filterPresets[strings.ToLower(name)]
+	return preset, ok
+}
+
+// FilterPresets returns the registered presets sorted by name
+func FilterPresets() []FilterPreset {
+	presets := make([]FilterPreset, 0, len(filterPresets))
+	for _, preset := range filterPresets {
+		presets = append(presets, preset)
+	}
+
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].Name < presets[j].Name
+	})
+
+	return presets
+}
+
+// HasValue reports whether the preset accepts a value
+func (p FilterPreset) HasValue() bool {
+	return p.build != nil && p.MaxValue > p.MinValue
+}
+
+// Spec returns the string form of the preset with a value, e.g. "speed:1.5",
+// suitable for storing and parsing with ParseFilter
+func (p FilterPreset) Spec(value float64) (string, error) {
+	spec := p.Name
+	if p.HasValue() && value != 0 {
+		spec = fmt.Sprintf("%s:%g", p.Name, value)
+	}
+
+	if _, err := ParseFilter(spec); err != nil {
+		return "", err
+	}
+
+	return spec, nil
+}
+
+// FilterTempo returns the factor the filter spec speeds playback up by, which
+// is 1 for filters that leave the tempo alone
+func FilterTempo(spec string) float64 {
+	name, rawValue, hasValue := strings.Cut(spec, ":")
+	preset, ok := filterPresets[strings.ToLower(name)]
+	if !ok || !preset.tempo {
+		return 1
+	}
+
+	if hasValue {
+		if value, err := strconv.ParseFloat(rawValue, 64); err == nil && value >= preset.MinValue && value <= preset.MaxValue {
+			return value
+		}
+	}
+
+	return preset.DefaultValue
+}
+
+// ParseFilter converts a filter spec of the form "name" or "name:value" into
+// an ffmpeg audio filter
+func ParseFilter(spec string) (string, error) {
+	name, rawValue, hasValue := strings.Cut(spec, ":")
+	preset, ok := filterPresets[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("unknown filter: %s", name)
+	}
+
+	value := preset.DefaultValue
+	if hasValue && preset.HasValue() {
+		parsed, err := strconv.ParseFloat(rawValue, 64)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse value for filter %s: %s", name, err)
+		}
+
+		if parsed < preset.MinValue || parsed > preset.MaxValue {
+			return "", fmt.Errorf("value for filter %s must be between %g and %g, got: %g", name, preset.MinValue, preset.MaxValue, parsed)
+		}
+
+		value = parsed
+	}
+
+	return preset.build(value), nil
+}
@@ -10,29 +10,45 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+type Chapter struct {
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+	Title     string  `json:"title"`
+}
+
 type Info struct {
-	ID             string `json:"id"`
-	Title          string `json:"title"`
-	Thumbnail      string `json:"thumbnail"`
-	Uploader       string `json:"uploader"`
-	UploaderID     string `json:"uploader_id"`
-	UploaderURL    string `json:"uploader_url"`
-	ChannelID      string `json:"channel_id"`
-	ChannelURL     string `json:"channel_url"`
-	Duration       int    `json:"duration"`
-	DurationString string `json:"duration_string"`
-	FormatID       string `json:"format_id"`
-	Filename       string `json:"filename"`
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	Thumbnail      string    `json:"thumbnail"`
+	Uploader       string    `json:"uploader"`
+	UploaderID     string    `json:"uploader_id"`
+	UploaderURL    string    `json:"uploader_url"`
+	ChannelID      string    `json:"channel_id"`
+	ChannelURL     string    `json:"channel_url"`
+	Duration       int       `json:"duration"`
+	DurationString string    `json:"duration_string"`
+	FormatID       string    `json:"format_id"`
+	Filename       string    `json:"filename"`
+	WebpageURL     string    `json:"webpage_url"`
+	ExtractorKey   string    `json:"extractor_key"`
+	IsLive         bool      `json:"is_live"`
+	Chapters       []Chapter `json:"chapters"`
 }
 
 func (e *Executor) GetInfo(id string) (*Info, error) {
+	return e.GetInfoByURL(fmt.Sprintf("https://youtube.com/watch?v=%s", id))
+}
+
+// GetInfoByURL returns the metadata of any page supported by yt-dlp
+func (e *Executor) GetInfoByURL(url string) (*Info, error) {
 	var info Info
 
 	job := func(ctx context.Context) error {
 		cmd := exec.CommandContext(ctx, e.YTDLPExecutable,
-			fmt.Sprintf("https://youtube.com/watch?v=%s", id),
+			url,
 			"--cache-dir", e.CacheDirectory,
 			"--dump-json",
+			"--no-playlist",
 		)
 
 		raw, err := cmd.Output()
@@ -45,14 +61,14 @@ func (e *Executor) GetInfo(id string) (*Info, error) {
 		}
 
 		if err := json.Unmarshal(raw, &info); err != nil {
-			return fmt.Errorf("failed to parse output for %s: %s", id, err)
+			return fmt.Errorf("failed to parse output for %s: %s", url, err)
 		}
 
 		return nil
 	}
 
-	if err := e.execute(fmt.Sprintf("infojson:%s", id), job); err != nil {
-		return nil, fmt.Errorf("failed to get info json for %s: %s", id, err)
+	if err := e.execute(fmt.Sprintf("infojson:%s", url), job); err != nil {
+		return nil, fmt.Errorf("failed to get info json for %s: %s", url, err)
 	}
 
 	return &info, nil
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// StreamLive reads a live stream from the url with ffmpeg and encodes it like
+// Encode, it never ends on its own so the returned reader must be closed to
+// stop the processes
+func (e *Executor) StreamLive(ctx context.Context, url string, filters ...string) (io.ReadCloser, error) {
+	build := func(ctx context.Context) []*exec.Cmd {
+		input := []string{
+			"-reconnect", "1",
+			"-reconnect_streamed", "1",
+			"-reconnect_delay_max", "5",
+			"-i", url,
+		}
+
+		return e.encodeCommands(ctx, input, filters)
+	}
+
+	stream, err := e.startStream(ctx, nil, build)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream %s: %s", url, err)
+	}
+
+	return stream, nil
+}
+
+// GetStreamURL returns the url of the best audio stream of a page supported
+// by yt-dlp, for live pages this is usually a hls manifest
+func (e *Executor) GetStreamURL(url string) (string, error) {
+	var streamURL string
+
+	job := func(ctx context.Context) error {
+		cmd := exec.CommandContext(ctx, e.YTDLPExecutable,
+			url,
+			"--cache-dir", e.CacheDirectory,
+			"--no-playlist",
+			"--format", "bestaudio/best",
+			"--get-url",
+		)
+
+		raw, err := cmd.Output()
+		if err != nil {
+			if err, ok := err.(*exec.ExitError); ok && len(err.Stderr) > 0 {
+				log.Warn().Bytes("stderr", err.Stderr).Msg("stderr was not empty")
+			}
+
+			return fmt.Errorf("failed to execute '%s %s': %s", e.YTDLPExecutable, strings.Join(cmd.Args, " "), err)
+		}
+
+		// formats with separate video and audio print one url per line
+		lines := strings.Fields(string(raw))
+		if len(lines) < 1 {
+			return fmt.Errorf("no stream url found for %s", url)
+		}
+
+		streamURL = lines[len(lines)-1]
+		return nil
+	}
+
+	if err := e.execute(fmt.Sprintf("streamurl:%s", url), job); err != nil {
+		return "", fmt.Errorf("failed to get stream url for %s: %s", url, err)
+	}
+
+	return streamURL, nil
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// how long to wait for pipes to drain after a process is killed
+	streamWaitDelay = time.Second * 5
+)
+
+// stream is the stdout of a chain of running processes, closing it kills any
+// process still running and waits for all of them to exit
+type stream struct {
+	stdout io.Reader
+	eof    atomic.Bool
+	cancel context.CancelFunc
+	cmds   []*exec.Cmd
+	stderr []*bytes.Buffer
+	// frees the slot taken from the executor stream limit
+	release func()
+	once    sync.Once
+	err     error
+}
+
+// startStream starts each command with its stdin connected to the stdout of
+// the previous one, returning the stdout of the last command
+//
+// streams are paced by their consumer rather than by the executor queue, so
+// they are limited separately by StreamConcurrency until they are closed
+func (e *Executor) startStream(ctx context.Context, in io.Reader, build func(ctx context.Context) []*exec.Cmd) (io.ReadCloser, error) {
+	release, err := e.acquireStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := stream{cancel: cancel, cmds: build(ctx), release: release}
+
+	var stdout io.ReadCloser
+	for i, cmd := range s.cmds {
+		if i == 0 {
+			cmd.Stdin = in
+		} else {
+			cmd.Stdin = stdout
+		}
+
+		stderr := bytes.Buffer{}
+		cmd.Stderr = &stderr
+		cmd.WaitDelay = streamWaitDelay
+		s.stderr = append(s.stderr, &stderr)
+
+		pipe, err := cmd.StdoutPipe()
+		if err != nil {
+			cancel()
+			release()
+			return nil, fmt.Errorf("failed to get stdout for %s: %s", cmd.String(), err)
+		}
+
+		stdout = pipe
+	}
+
+	for i, cmd := range s.cmds {
+		if err := cmd.Start(); err != nil {
+			cancel()
+			for _, started := range s.cmds[:i] {
+				started.Wait()
+			}
+
+			release()
+			return nil, fmt.Errorf("failed to start %s: %s", cmd.String(), err)
+		}
+	}
+
+	s.stdout = stdout
+	return &s, nil
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	n, err := s.stdout.Read(p)
+	if err == io.EOF {
+		s.eof.Store(true)
+	}
+
+	return n, err
+}
+
+func (s *stream) Close() error {
+	s.once.Do(func() {
+		// processes that were stopped early are killed, anything that exits
+		// by itself after the consumer has read everything is waited on
+		killed := !s.eof.Load()
+		if killed {
+			s.cancel()
+		}
+
+		defer s.release()
+		defer s.cancel()
+
+		for i := len(s.cmds) - 1; i > -1; i-- {
+			cmd := s.cmds[i]
+			if err := cmd.Wait(); err != nil && !killed && s.err == nil {
+				if s.stderr[i].Len() > 0 {
+					log.Warn().Bytes("stderr", s.stderr[i].Bytes()).Msg("stderr was not empty")
+				}
+
+				s.err = fmt.Errorf("failed to execute '%s': %s", strings.Join(cmd.Args, " "), err)
+			}
+		}
+	})
+
+	return s.err
+}
+
+// acquireStream waits for a free slot in the stream limit, the returned func
+// gives it back
+func (e *Executor) acquireStream(ctx context.Context) (func(), error) {
+	if e.streams == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case e.streams <- struct{}{}:
+		return func() { <-e.streams }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to start stream: %s", ctx.Err())
+	}
+}
@@ -0,0 +1,86 @@
+package cmds
+
+import (
+	"context"
+	"strings"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Autoplay)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Autoplay)(nil)
+	_ discord.PermissionedCommand                  = (*Autoplay)(nil)
+)
+
+type Autoplay struct{ Players *player.Manager }
+
+func (cmd Autoplay) Name() string {
+	return "autoplay"
+}
+
+func (cmd Autoplay) Description() string {
+	return "Keep playing related songs when the queue runs out"
+}
+
+func (cmd Autoplay) Permission() discord.Permission {
+	return discord.PermissionDJ
+}
+
+func (cmd Autoplay) run(guildID string, enabled *bool) string {
+	if guildID == "" {
+		return guildOnlyReply
+	}
+
+	guildPlayer := cmd.Players.Get(guildID)
+	if enabled == nil {
+		toggled := !guildPlayer.Autoplay()
+		enabled = &toggled
+	}
+
+	guildPlayer.SetAutoplay(*enabled)
+	if *enabled {
+		return "♾️ autoplay on"
+	}
+
+	return "♾️ autoplay off"
+}
+
+func (cmd Autoplay) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	var enabled *bool
+	if len(args) > 0 {
+		value := strings.EqualFold(args[0], "on")
+		enabled = &value
+	}
+
+	if err := bot.SendMessageReply(ctx, event.Message, cmd.run(event.GuildID, enabled)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Autoplay) ApplicationCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+		Options: []*discordgo.ApplicationCommandOption{{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "enabled",
+			Description: "turn autoplay on or off, toggles if omitted",
+		}},
+	}
+}
+
+func (cmd Autoplay) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	opts := resolveOptions(data.Options)
+	var enabled *bool
+	if value, ok := opts["enabled"].(bool); ok {
+		enabled = &value
+	}
+
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, cmd.run(event.GuildID, enabled)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
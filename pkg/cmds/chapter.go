@@ -0,0 +1,92 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/axatol/guosheng/pkg/util"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Chapter)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Chapter)(nil)
+	_ discord.PermissionedCommand                  = (*Chapter)(nil)
+)
+
+type Chapter struct{ Players *player.Manager }
+
+func (cmd Chapter) Name() string {
+	return "chapter"
+}
+
+func (cmd Chapter) Description() string {
+	return "Jump between chapters of the current song"
+}
+
+func (cmd Chapter) Permission() discord.Permission {
+	return discord.PermissionDJ
+}
+
+func (cmd Chapter) run(guildID, input string) string {
+	guildPlayer, ok := cmd.Players.Lookup(guildID)
+	if !ok || guildPlayer.Current() == nil {
+		return "nothing is playing"
+	}
+
+	if input == "" {
+		index, chapters := guildPlayer.CurrentChapter()
+		if index < 0 {
+			return "the current track has no chapters"
+		}
+
+		lines := make([]string, len(chapters))
+		for i, chapter := range chapters {
+			line := fmt.Sprintf("`%s` %s", util.FormatDuration(chapter.Start), chapter.Title)
+			if i == index {
+				line = fmt.Sprintf("**%s**", line)
+			}
+
+			lines[i] = line
+		}
+
+		return truncate(strings.Join(lines, "\n"), 2000)
+	}
+
+	chapter, err := guildPlayer.SeekChapter(input)
+	if err != nil {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("⏩ %s (%s)", chapter.Title, util.FormatDuration(chapter.Start))
+}
+
+func (cmd Chapter) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	if err := bot.SendMessageReply(ctx, event.Message, cmd.run(event.GuildID, strings.Join(args, " "))); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Chapter) ApplicationCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+		Options: []*discordgo.ApplicationCommandOption{{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "target",
+			Description: "next, prev or part of a chapter name, lists the chapters if omitted",
+		}},
+	}
+}
+
+func (cmd Chapter) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	opts := resolveOptions(data.Options)
+	target, _ := opts["target"].(string)
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, cmd.run(event.GuildID, target)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
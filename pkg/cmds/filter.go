@@ -0,0 +1,210 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/axatol/guosheng/pkg/cli"
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Filter)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Filter)(nil)
+	_ discord.PermissionedCommand                  = (*Filter)(nil)
+)
+
+type Filter struct{ Players *player.Manager }
+
+func (cmd Filter) Name() string {
+	return "filter"
+}
+
+func (cmd Filter) Description() string {
+	return "Apply audio filters such as bassboost or nightcore"
+}
+
+func (cmd Filter) Permission() discord.Permission {
+	return discord.PermissionDJ
+}
+
+func (cmd Filter) add(ctx context.Context, guildID, name string, value float64) string {
+	preset, ok := cli.GetFilterPreset(name)
+	if !ok {
+		return fmt.Sprintf("unknown filter %s", name)
+	}
+
+	spec, err := preset.Spec(value)
+	if err != nil {
+		return err.Error()
+	}
+
+	guildPlayer := cmd.Players.Get(guildID)
+	if err := guildPlayer.AddFilter(ctx, spec); err != nil {
+		log.Warn().Err(err).Send()
+		return err.Error()
+	}
+
+	return listFilters(guildPlayer.Settings())
+}
+
+func (cmd Filter) remove(ctx context.Context, guildID, name string) string {
+	guildPlayer := cmd.Players.Get(guildID)
+	if err := guildPlayer.RemoveFilter(ctx, name); err != nil {
+		log.Warn().Err(err).Send()
+		return err.Error()
+	}
+
+	return listFilters(guildPlayer.Settings())
+}
+
+func (cmd Filter) clear(ctx context.Context, guildID string) string {
+	guildPlayer := cmd.Players.Get(guildID)
+	if err := guildPlayer.ClearFilters(ctx); err != nil {
+		log.Warn().Err(err).Send()
+		return err.Error()
+	}
+
+	return listFilters(guildPlayer.Settings())
+}
+
+// list shows the filters without starting a player for the guild
+func (cmd Filter) list(ctx context.Context, guildID string) string {
+	settings, err := cmd.Players.Settings(ctx, guildID)
+	if err != nil {
+		log.Warn().Err(err).Send()
+		return "could not load filters"
+	}
+
+	return listFilters(settings)
+}
+
+func listFilters(settings player.Settings) string {
+	filters := settings.Filters
+	if len(filters) < 1 {
+		return "no filters applied"
+	}
+
+	return fmt.Sprintf("filters applied: `%s`", strings.Join(filters, "` → `"))
+}
+
+func (cmd Filter) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	var reply string
+	switch {
+	case event.GuildID == "":
+		reply = guildOnlyReply
+	case len(args) < 1:
+		reply = cmd.list(ctx, event.GuildID)
+	case args[0] == "add" && len(args) > 1:
+		var value float64
+		if len(args) > 2 {
+			parsed, err := strconv.ParseFloat(args[2], 64)
+			if err != nil {
+				reply = fmt.Sprintf("invalid value %s", args[2])
+				break
+			}
+
+			value = parsed
+		}
+
+		reply = cmd.add(ctx, event.GuildID, args[1], value)
+	case args[0] == "remove" && len(args) > 1:
+		reply = cmd.remove(ctx, event.GuildID, args[1])
+	case args[0] == "clear":
+		reply = cmd.clear(ctx, event.GuildID)
+	default:
+		reply = fmt.Sprintf("usage: `%s%s [add <name> [value] | remove <name> | clear]`", bot.MessagePrefix, cmd.Name())
+	}
+
+	if err := bot.SendMessageReply(ctx, event.Message, reply); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Filter) ApplicationCommand() *discordgo.ApplicationCommand {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	var descriptions []string
+	for _, preset := range cli.FilterPresets() {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: preset.Name, Value: preset.Name})
+		if preset.HasValue() {
+			descriptions = append(descriptions, fmt.Sprintf("%s %g-%g", preset.Name, preset.MinValue, preset.MaxValue))
+		}
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add a filter, replacing it if already applied",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "filter name",
+						Required:    true,
+						Choices:     choices,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionNumber,
+						Name:        "value",
+						Description: truncate(strings.Join(descriptions, ", "), 100),
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove a filter",
+				Options: []*discordgo.ApplicationCommandOption{{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "filter name",
+					Required:    true,
+					Choices:     choices,
+				}},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "clear",
+				Description: "Remove all filters",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List the applied filters",
+			},
+		},
+	}
+}
+
+func (cmd Filter) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	opts := resolveOptions(data.Options)
+
+	var reply string
+	if event.GuildID == "" {
+		reply = guildOnlyReply
+	} else if add, ok := opts["add"].(map[string]any); ok {
+		name, _ := add["name"].(string)
+		value, _ := add["value"].(float64)
+		reply = cmd.add(ctx, event.GuildID, name, value)
+	} else if remove, ok := opts["remove"].(map[string]any); ok {
+		name, _ := remove["name"].(string)
+		reply = cmd.remove(ctx, event.GuildID, name)
+	} else if _, ok := opts["clear"]; ok {
+		reply = cmd.clear(ctx, event.GuildID)
+	} else {
+		reply = cmd.list(ctx, event.GuildID)
+	}
+
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, reply); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
@@ -0,0 +1,307 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*History)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*History)(nil)
+	_ discord.MessageComponentInteractionHandler   = (*History)(nil)
+)
+
+const historyPageSize = 10
+
+var historyOutcomeEmoji = map[player.HistoryOutcome]string{
+	player.HistoryPlaying:   "▶️",
+	player.HistoryCompleted: "✅",
+	player.HistorySkipped:   "⏭️",
+	player.HistoryStopped:   "⏹️",
+	player.HistoryFailed:    "⚠️",
+}
+
+type History struct{ Players *player.Manager }
+
+func (cmd History) Name() string {
+	return "history"
+}
+
+func (cmd History) Description() string {
+	return "Show recently played songs"
+}
+
+// run renders a page of the history, with a menu to replay anything on it,
+// errors are meant to be shown to the user
+func (cmd History) run(ctx context.Context, guildID string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	history, err := cmd.Players.History(ctx, guildID)
+	if err != nil {
+		log.Warn().Err(err).Send()
+		return nil, nil, fmt.Errorf("could not load history")
+	}
+
+	if len(history) < 1 {
+		return nil, nil, fmt.Errorf("nothing has been played yet")
+	}
+
+	pages := (len(history) + historyPageSize - 1) / historyPageSize
+	page = min(max(page, 0), pages-1)
+	start := page * historyPageSize
+	entries := history[start:min(start+historyPageSize, len(history))]
+
+	embed := discord.NewMessageEmbed().
+		SetTitle("History").
+		SetFooter(fmt.Sprintf("page %d/%d", page+1, pages))
+
+	options := make([]discordgo.SelectMenuOption, len(entries))
+	for i, entry := range entries {
+		requester := "autoplay"
+		if entry.Track.RequesterID != "" {
+			requester = fmt.Sprintf("<@%s>", entry.Track.RequesterID)
+		}
+
+		embed.AddField(
+			fmt.Sprintf("%d. %s", start+i+1, truncate(entry.Track.Title, 200)),
+			fmt.Sprintf("%s %s, requested by %s <t:%d:R>", historyOutcomeEmoji[entry.Outcome], entry.Outcome, requester, entry.StartedAt.Unix()),
+			false,
+		)
+
+		// entries shift as more is played so they are found by when they started
+		options[i] = discordgo.SelectMenuOption{
+			Label:       truncate(fmt.Sprintf("%d. %s", start+i+1, entry.Track.Title), 100),
+			Description: truncate(fmt.Sprintf("uploaded by %s", entry.Track.Uploader), 100),
+			Value:       fmt.Sprint(entry.StartedAt.UnixNano()),
+		}
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					Placeholder: "Select items to replay",
+					CustomID:    fmt.Sprintf("%s:replay", cmd.Name()),
+					MenuType:    discordgo.StringSelectMenu,
+					MaxValues:   len(options),
+					Options:     options,
+				},
+			},
+		},
+	}
+
+	if pages > 1 {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: "Previous", Emoji: discordgo.ComponentEmoji{Name: "◀️"}, Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("%s:page/%d", cmd.Name(), page-1), Disabled: page < 1},
+				discordgo.Button{Label: "Next", Emoji: discordgo.ComponentEmoji{Name: "▶️"}, Style: discordgo.SecondaryButton, CustomID: fmt.Sprintf("%s:page/%d", cmd.Name(), page+1), Disabled: page >= pages-1},
+			},
+		})
+	}
+
+	return embed.Embed(), components, nil
+}
+
+// replay queues the selected entries again in the voice channel of the user,
+// errors are meant to be shown to the user
+func (cmd History) replay(ctx context.Context, guildID, channelID, textChannelID string, user *discordgo.User, values []string) ([]*discordgo.MessageEmbed, error) {
+	history, err := cmd.Players.History(ctx, guildID)
+	if err != nil {
+		log.Warn().Err(err).Send()
+		return nil, fmt.Errorf("could not load history")
+	}
+
+	var tracks []*player.Track
+	for _, value := range values {
+		nanos, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range history {
+			if entry.StartedAt.Equal(time.Unix(0, nanos)) {
+				track := *entry.Track
+				track.RequesterID = user.ID
+				track.Autoplay = false
+				tracks = append(tracks, &track)
+				break
+			}
+		}
+	}
+
+	if len(tracks) < 1 {
+		return nil, fmt.Errorf("those songs are no longer in the history")
+	}
+
+	guildPlayer := cmd.Players.Get(guildID)
+	guildPlayer.SetTextChannel(textChannelID)
+	embeds := make([]*discordgo.MessageEmbed, len(tracks))
+	for i, track := range tracks {
+		embed := track.MessageEmbed()
+		if position := guildPlayer.Enqueue(channelID, track); position > 0 {
+			embed.AddField("Position", fmt.Sprint(position))
+		}
+
+		embeds[i] = embed.Embed()
+	}
+
+	return embeds, nil
+}
+
+func (cmd History) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	page := 1
+	if len(args) > 0 {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil {
+			if err := bot.SendMessageReply(ctx, event.Message, fmt.Sprintf("usage: `%s%s [page]`", bot.MessagePrefix, cmd.Name())); err != nil {
+				log.Warn().Err(err).Send()
+			}
+
+			return
+		}
+
+		page = parsed
+	}
+
+	embed, components, err := cmd.run(ctx, event.GuildID, page-1)
+	if err != nil {
+		if err := bot.SendMessageReply(ctx, event.Message, err.Error()); err != nil {
+			log.Warn().Err(err).Send()
+		}
+
+		return
+	}
+
+	send := discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+		Reference:  event.Message.Reference(),
+	}
+
+	if _, err := bot.Session.ChannelMessageSendComplex(event.ChannelID, &send, discord.RequestOptions(ctx)); err != nil {
+		log.Warn().Err(fmt.Errorf("failed to respond to message %s: %s", event.Message.ID, err)).Send()
+	}
+}
+
+func (cmd History) ApplicationCommand() *discordgo.ApplicationCommand {
+	minPage := float64(1)
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+		Options: []*discordgo.ApplicationCommandOption{{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "page",
+			Description: "page to show",
+			MinValue:    &minPage,
+		}},
+	}
+}
+
+func (cmd History) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	opts := resolveOptions(data.Options)
+	page, ok := opts["page"].(int64)
+	if !ok {
+		page = 1
+	}
+
+	embed, components, err := cmd.run(ctx, event.GuildID, int(page)-1)
+	if err != nil {
+		if err := bot.SendInteractionMessageReply(ctx, event.Interaction, err.Error()); err != nil {
+			log.Warn().Err(err).Send()
+		}
+
+		return
+	}
+
+	response := discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	}
+
+	if err := bot.SendInteractionReply(ctx, event.Interaction, &response); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd History) OnMessageComponent(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.MessageComponentInteractionData) {
+	customID := strings.Split(data.CustomID, ":")[1]
+	log := log.With().
+		Str("custom_id", customID).
+		Strs("values", data.Values).
+		Logger()
+
+	if pageID, ok := strings.CutPrefix(customID, "page/"); ok {
+		cmd.onPage(ctx, bot, event, pageID, log)
+		return
+	}
+
+	if customID != "replay" {
+		log.Debug().Err(fmt.Errorf("invalid history custom id")).Send()
+		return
+	}
+
+	// the history message is left alone, the queued songs are a new message
+	user := interactionUser(event)
+	guildID, channelID := bot.GetUserVoiceChannel(user.ID)
+	if guildID == "" || channelID == "" {
+		if err := bot.SendInteractionMessageReply(ctx, event.Interaction, "must be in a voice channel"); err != nil {
+			log.Warn().Err(err).Send()
+		}
+
+		return
+	}
+
+	response := discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{},
+	}
+
+	embeds, err := cmd.replay(ctx, guildID, channelID, event.ChannelID, user, data.Values)
+	if err != nil {
+		response.Data.Content = err.Error()
+	} else {
+		response.Data.Embeds = embeds
+	}
+
+	if err := bot.SendInteractionReply(ctx, event.Interaction, &response); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd History) onPage(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, pageID string, log zerolog.Logger) {
+	page, err := strconv.Atoi(pageID)
+	if err != nil {
+		log.Debug().Err(fmt.Errorf("invalid history page %s: %s", pageID, err)).Send()
+		return
+	}
+
+	response := discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{},
+			Components: []discordgo.MessageComponent{},
+		},
+	}
+
+	embed, components, err := cmd.run(ctx, event.GuildID, page)
+	if err != nil {
+		response.Data.Content = err.Error()
+	} else {
+		response.Data.Embeds = []*discordgo.MessageEmbed{embed}
+		response.Data.Components = components
+	}
+
+	if err := bot.SendInteractionReply(ctx, event.Interaction, &response); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
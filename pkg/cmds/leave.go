@@ -4,15 +4,17 @@ import (
 	"context"
 
 	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
 	"github.com/bwmarrin/discordgo"
 	"github.com/rs/zerolog/log"
 )
 
 var (
 	_ discord.ApplicationCommandInteractionHandler = (*Leave)(nil)
+	_ discord.PermissionedCommand                  = (*Leave)(nil)
 )
 
-type Leave struct{}
+type Leave struct{ Players *player.Manager }
 
 func (cmd Leave) Name() string {
 	return "leave"
@@ -22,6 +24,10 @@ func (cmd Leave) Description() string {
 	return "Leave your voice channel"
 }
 
+func (cmd Leave) Permission() discord.Permission {
+	return discord.PermissionDJ
+}
+
 func (cmd Leave) ApplicationCommand() *discordgo.ApplicationCommand {
 	return &discordgo.ApplicationCommand{
 		Name:        cmd.Name(),
@@ -35,6 +41,15 @@ func (cmd Leave) OnApplicationCommand(ctx context.Context, bot *discord.Bot, eve
 		log.Warn().Err(err).Send()
 	}
 
+	// stop playback first so the player releases the voice connection itself
+	if guildPlayer, ok := cmd.Players.Lookup(event.GuildID); ok {
+		if err := guildPlayer.Stop(); err != nil {
+			log.Warn().Err(err).Send()
+		}
+
+		return
+	}
+
 	if err := bot.LeaveUserVoiceChannel(event.Member.User.ID); err != nil {
 		log.Warn().Err(err).Send()
 		return
@@ -0,0 +1,91 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Loop)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Loop)(nil)
+	_ discord.PermissionedCommand                  = (*Loop)(nil)
+)
+
+type Loop struct{ Players *player.Manager }
+
+func (cmd Loop) Name() string {
+	return "loop"
+}
+
+func (cmd Loop) Description() string {
+	return "Repeat the current song or the whole queue"
+}
+
+func (cmd Loop) Permission() discord.Permission {
+	return discord.PermissionDJ
+}
+
+func (cmd Loop) run(guildID, input string) string {
+	if guildID == "" {
+		return guildOnlyReply
+	}
+
+	if input == "" {
+		mode := player.LoopOff
+		if guildPlayer, ok := cmd.Players.Lookup(guildID); ok {
+			mode = guildPlayer.Loop()
+		}
+
+		return fmt.Sprintf("🔁 %s", mode)
+	}
+
+	mode, err := player.ParseLoopMode(input)
+	if err != nil {
+		return err.Error()
+	}
+
+	cmd.Players.Get(guildID).SetLoop(mode)
+	return fmt.Sprintf("🔁 %s", mode)
+}
+
+func (cmd Loop) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	var input string
+	if len(args) > 0 {
+		input = args[0]
+	}
+
+	if err := bot.SendMessageReply(ctx, event.Message, cmd.run(event.GuildID, input)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Loop) ApplicationCommand() *discordgo.ApplicationCommand {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(player.LoopModes))
+	for i, mode := range player.LoopModes {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{Name: mode.String(), Value: mode.String()}
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+		Options: []*discordgo.ApplicationCommandOption{{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "mode",
+			Description: "what to repeat",
+			Choices:     choices,
+		}},
+	}
+}
+
+func (cmd Loop) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	opts := resolveOptions(data.Options)
+	mode, _ := opts["mode"].(string)
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, cmd.run(event.GuildID, mode)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
@@ -0,0 +1,139 @@
+package cmds
+
+import (
+	"context"
+	"strings"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*NowPlaying)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*NowPlaying)(nil)
+	_ discord.MessageComponentInteractionHandler   = (*NowPlaying)(nil)
+	_ discord.ComponentPermissionedCommand         = (*NowPlaying)(nil)
+)
+
+type NowPlaying struct{ Players *player.Manager }
+
+func (cmd NowPlaying) Name() string {
+	return player.NowPlayingCommandName
+}
+
+func (cmd NowPlaying) Description() string {
+	return "Show the current song with playback controls"
+}
+
+func (cmd NowPlaying) run(ctx context.Context, guildID, channelID string) string {
+	guildPlayer, ok := cmd.Players.Lookup(guildID)
+	if !ok || guildPlayer.Current() == nil {
+		return "nothing is playing"
+	}
+
+	if err := guildPlayer.PostNowPlaying(ctx, channelID); err != nil {
+		log.Warn().Err(err).Send()
+		return "failed to post now playing message"
+	}
+
+	return ""
+}
+
+func (cmd NowPlaying) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	if reply := cmd.run(ctx, event.GuildID, event.ChannelID); reply != "" {
+		if err := bot.SendMessageReply(ctx, event.Message, reply); err != nil {
+			log.Warn().Err(err).Send()
+		}
+	}
+}
+
+func (cmd NowPlaying) ApplicationCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+	}
+}
+
+func (cmd NowPlaying) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	reply := cmd.run(ctx, event.GuildID, event.ChannelID)
+	if reply == "" {
+		reply = "🎶"
+	}
+
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, reply); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+// ComponentPermission requires the dj role for the playback controls, skipping
+// is put to a vote instead
+func (cmd NowPlaying) ComponentPermission(interactionID string) discord.Permission {
+	if player.NowPlayingAction(interactionID) == player.NowPlayingSkip {
+		return discord.PermissionEveryone
+	}
+
+	return discord.PermissionDJ
+}
+
+func (cmd NowPlaying) OnMessageComponent(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.MessageComponentInteractionData) {
+	action := player.NowPlayingAction(strings.Split(data.CustomID, ":")[1])
+
+	log := log.With().
+		Str("guild_id", event.GuildID).
+		Str("action", string(action)).
+		Logger()
+
+	guildPlayer, ok := cmd.Players.Lookup(event.GuildID)
+	if !ok {
+		if err := bot.SendInteractionDeferral(ctx, event.Interaction); err != nil {
+			log.Warn().Err(err).Send()
+		}
+
+		return
+	}
+
+	switch action {
+	case player.NowPlayingPause:
+		guildPlayer.Pause()
+	case player.NowPlayingResume:
+		guildPlayer.Resume()
+	case player.NowPlayingSkip:
+		if _, err := voteSkip(ctx, bot, guildPlayer, event.ChannelID, interactionUser(event), event.Member); err != nil {
+			log.Debug().Err(err).Send()
+		}
+	case player.NowPlayingStop:
+		if err := guildPlayer.Stop(); err != nil {
+			log.Warn().Err(err).Send()
+		}
+	case player.NowPlayingLoop:
+		guildPlayer.SetLoop(nextLoopMode(guildPlayer.Loop()))
+	case player.NowPlayingShuffle:
+		guildPlayer.Shuffle()
+	default:
+		log.Warn().Msg("unknown now playing action")
+	}
+
+	response := discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{guildPlayer.NowPlayingEmbed()},
+			Components: guildPlayer.NowPlayingComponents(),
+		},
+	}
+
+	if err := bot.SendInteractionReply(ctx, event.Interaction, &response); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func nextLoopMode(mode player.LoopMode) player.LoopMode {
+	for i, candidate := range player.LoopModes {
+		if candidate == mode {
+			return player.LoopModes[(i+1)%len(player.LoopModes)]
+		}
+	}
+
+	return player.LoopOff
+}
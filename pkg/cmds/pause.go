@@ -0,0 +1,58 @@
+package cmds
+
+import (
+	"context"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Pause)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Pause)(nil)
+	_ discord.PermissionedCommand                  = (*Pause)(nil)
+)
+
+type Pause struct{ Players *player.Manager }
+
+func (cmd Pause) Name() string {
+	return "pause"
+}
+
+func (cmd Pause) Description() string {
+	return "Pause the current song"
+}
+
+func (cmd Pause) Permission() discord.Permission {
+	return discord.PermissionDJ
+}
+
+func (cmd Pause) run(guildID string) string {
+	guildPlayer, ok := cmd.Players.Lookup(guildID)
+	if !ok || !guildPlayer.Pause() {
+		return "nothing is playing"
+	}
+
+	return "⏸️"
+}
+
+func (cmd Pause) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	if err := bot.SendMessageReply(ctx, event.Message, cmd.run(event.GuildID)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Pause) ApplicationCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+	}
+}
+
+func (cmd Pause) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, cmd.run(event.GuildID)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
@@ -1,15 +1,15 @@
 package cmds
 
 import (
-	"bytes"
 	"context"
-	"encoding/binary"
+	"errors"
 	"fmt"
-	"io"
+	"strings"
+	"time"
 
-	"github.com/axatol/guosheng/pkg/cache"
-	"github.com/axatol/guosheng/pkg/cli"
 	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/axatol/guosheng/pkg/source"
 	"github.com/axatol/guosheng/pkg/util"
 	"github.com/axatol/guosheng/pkg/yt"
 	"github.com/bwmarrin/discordgo"
@@ -17,13 +17,14 @@ import (
 )
 
 var (
+	_ discord.MessageHandler                       = (*Play)(nil)
 	_ discord.ApplicationCommandInteractionHandler = (*Play)(nil)
 )
 
 type Play struct {
-	YouTube     *yt.Client
-	CLI         *cli.Executor
-	ObjectStore cache.ObjectStore
+	YouTube       *yt.Client
+	Players       *player.Manager
+	PlaylistLimit int
 }
 
 func (cmd Play) Name() string {
@@ -34,6 +35,149 @@ func (cmd Play) Description() string {
 	return "Play a song"
 }
 
+// run resolves the inputs and queues them in the voice channel of the user,
+// errors are meant to be shown to the user
+func (cmd Play) run(ctx context.Context, guildID, channelID, textChannelID string, user *discordgo.User, inputs ...string) (*discordgo.MessageEmbed, error) {
+	// a watch url within a playlist plays just the video
+	if len(inputs) == 1 {
+		if playlistID := yt.GetPlaylistIDFromURL(inputs[0]); playlistID != "" && yt.GetVideoIDFromURL(inputs[0]) == "" {
+			return cmd.runPlaylist(ctx, guildID, channelID, textChannelID, playlistID, user)
+		}
+	}
+
+	var tracks []*player.Track
+	for _, input := range inputs {
+		resolved, err := cmd.Players.Resolve(ctx, input)
+		if err != nil {
+			log.Warn().Err(err).Send()
+			return nil, errors.New("could not play that")
+		}
+
+		tracks = append(tracks, resolved...)
+	}
+
+	if len(tracks) < 1 {
+		return nil, errors.New("nothing to play")
+	}
+
+	for _, track := range tracks {
+		track.RequesterID = user.ID
+	}
+
+	guildPlayer := cmd.Players.Get(guildID)
+	guildPlayer.SetTextChannel(textChannelID)
+	position := guildPlayer.Enqueue(channelID, tracks...)
+
+	embed := tracks[0].MessageEmbed()
+	if len(tracks) > 1 {
+		embed = discord.NewMessageEmbed().
+			SetTitle(fmt.Sprintf("Added %d tracks", len(tracks)))
+		for _, track := range tracks {
+			embed.AddField(track.Title, track.DurationString(), false)
+		}
+	}
+
+	if position > 0 {
+		embed.AddField("Position", fmt.Sprint(position))
+	}
+
+	return embed.Embed(), nil
+}
+
+func (cmd Play) runPlaylist(ctx context.Context, guildID, channelID, textChannelID, playlistID string, user *discordgo.User) (*discordgo.MessageEmbed, error) {
+	playlist, err := cmd.YouTube.GetPlaylistByID(ctx, playlistID)
+	if err != nil {
+		log.Warn().Err(err).Send()
+		return nil, errors.New("could not find that playlist")
+	}
+
+	videos, unavailable, err := cmd.YouTube.GetPlaylistVideos(ctx, playlistID, cmd.PlaylistLimit)
+	if err != nil {
+		log.Warn().Err(err).Send()
+		return nil, errors.New("could not load that playlist")
+	}
+
+	if len(videos) < 1 {
+		return nil, errors.New("playlist has no playable videos")
+	}
+
+	tracks := make([]*player.Track, len(videos))
+	for i := range videos {
+		tracks[i] = newTrackFromVideo(&videos[i], user)
+	}
+
+	guildPlayer := cmd.Players.Get(guildID)
+	guildPlayer.SetTextChannel(textChannelID)
+	position := guildPlayer.Enqueue(channelID, tracks...)
+
+	var total time.Duration
+	for _, track := range tracks {
+		total += track.Duration
+	}
+
+	embed := discord.NewMessageEmbed().
+		SetTitle(playlist.Title).
+		SetURL(playlist.PlaylistURL()).
+		AddField("Channel", util.MDLink(playlist.ChannelTitle, playlist.ChannelURL())).
+		AddField("Added", fmt.Sprint(len(tracks))).
+		AddField("Duration", util.FormatDuration(total))
+
+	if unavailable > 0 {
+		embed.AddField("Skipped", fmt.Sprintf("%d unavailable", unavailable))
+	}
+
+	if remaining := int(playlist.ItemCount) - len(tracks) - unavailable; remaining > 0 {
+		embed.SetFooter(fmt.Sprintf("limited to the first %d items, %d not added", cmd.PlaylistLimit, remaining))
+	}
+
+	if position > 0 {
+		embed.AddField("Position", fmt.Sprint(position))
+	}
+
+	return embed.Embed(), nil
+}
+
+func (cmd Play) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	inputs := args
+	if len(inputs) < 1 {
+		for _, attachment := range event.Message.Attachments {
+			if isAudioAttachment(attachment) {
+				inputs = append(inputs, attachment.URL)
+			}
+		}
+	}
+
+	if len(inputs) < 1 {
+		if err := bot.SendMessageReply(ctx, event.Message, "must provide a link or attach an audio file"); err != nil {
+			log.Warn().Err(err).Send()
+		}
+
+		return
+	}
+
+	guildID, channelID := bot.GetUserVoiceChannel(event.Author.ID)
+	if guildID == "" || channelID == "" {
+		if err := bot.SendMessageReply(ctx, event.Message, "must be in a voice channel"); err != nil {
+			log.Warn().Err(err).Send()
+		}
+
+		return
+	}
+
+	embed, err := cmd.run(ctx, guildID, channelID, event.ChannelID, event.Author, inputs...)
+	if err != nil {
+		if err := bot.SendMessageReply(ctx, event.Message, err.Error()); err != nil {
+			log.Warn().Err(err).Send()
+		}
+
+		return
+	}
+
+	if err := bot.SendMessageEmbedReply(ctx, event.Message, embed); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
 func (cmd Play) ApplicationCommand() *discordgo.ApplicationCommand {
 	return &discordgo.ApplicationCommand{
 		Name:        cmd.Name(),
@@ -42,27 +186,36 @@ func (cmd Play) ApplicationCommand() *discordgo.ApplicationCommand {
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "url",
-				Description: "specify a url",
+				Description: "specify a link to a video, playlist or audio file",
 			},
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "video_id",
 				Description: "specify a video id",
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionAttachment,
+				Name:        "attachment",
+				Description: "upload an audio file",
+			},
 		},
 	}
 }
 
 func (cmd Play) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
 	opts := resolveOptions(data.Options)
-	videoID, ok := opts["video_id"].(string)
+	input, ok := opts["video_id"].(string)
 	if !ok {
-		if url, ok := opts["url"].(string); ok {
-			videoID = yt.GetVideoIDFromURL(url)
+		input, _ = opts["url"].(string)
+	}
+
+	if attachmentID, ok := opts["attachment"].(string); ok && input == "" && data.Resolved != nil {
+		if attachment, ok := data.Resolved.Attachments[attachmentID]; ok {
+			input = attachment.URL
 		}
 	}
 
-	if videoID == "" {
+	if input == "" {
 		if err := bot.SendInteractionMessageReply(ctx, event.Interaction, "must provide an input"); err != nil {
 			log.Warn().Err(err).Send()
 		}
@@ -70,114 +223,34 @@ func (cmd Play) OnApplicationCommand(ctx context.Context, bot *discord.Bot, even
 		return
 	}
 
-	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, "🤔"); err != nil {
-		log.Warn().Err(err).Send()
-	}
+	user := interactionUser(event)
+	guildID, channelID := bot.GetUserVoiceChannel(user.ID)
+	if guildID == "" || channelID == "" {
+		if err := bot.SendInteractionMessageReply(ctx, event.Interaction, "must be in a voice channel"); err != nil {
+			log.Warn().Err(err).Send()
+		}
 
-	item, err := cmd.YouTube.GetVideoByID(ctx, videoID)
-	if err != nil {
-		log.Warn().Err(err).Send()
 		return
 	}
 
-	uploader := util.MDLink(item.ChannelTitle, item.ChannelURL())
-	duration := item.Duration().String()
-	if duration == "" {
-		duration = "?"
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, "🤔"); err != nil {
+		log.Warn().Err(err).Send()
 	}
 
-	edit := discordgo.WebhookEdit{
-		Content: new(string),
-		Embeds: &[]*discordgo.MessageEmbed{
-			discord.NewMessageEmbed().
-				SetTitle(item.Title).
-				SetURL(item.VideoURL()).
-				AddField("Uploader", uploader).
-				AddField("Duration", duration).
-				Embed(),
-		},
+	edit := discordgo.WebhookEdit{Content: new(string)}
+	embed, err := cmd.run(ctx, guildID, channelID, event.ChannelID, user, input)
+	if err != nil {
+		content := err.Error()
+		edit.Content = &content
+	} else {
+		edit.Embeds = &[]*discordgo.MessageEmbed{embed}
 	}
 
 	if err := bot.SendInteractionEdit(ctx, event.Interaction, &edit); err != nil {
 		log.Warn().Err(err).Send()
-		return
 	}
+}
 
-	go func() {
-		user := event.User
-		if user == nil {
-			user = event.Member.User
-		}
-
-		vc, err := bot.JoinUserVoiceChannel(user.ID)
-		if err != nil {
-			log.Error().Err(err).Send()
-			return
-		}
-
-		cacheKey := fmt.Sprintf("cache/%s", item.ID)
-		if _, err := cmd.ObjectStore.Stat(ctx, cacheKey); err != nil {
-			if err != cache.ErrObjectNotFound {
-				log.Error().Err(err).Send()
-				return
-			}
-
-			raw, err := cmd.CLI.Download(item.ID)
-			if err != nil {
-				log.Error().Err(err).Send()
-				return
-			}
-
-			if _, err := cmd.ObjectStore.Put(ctx, cacheKey, raw, item.ToMap()); err != nil {
-				log.Error().Err(err).Send()
-				return
-			}
-		}
-
-		raw, err := cmd.ObjectStore.Get(ctx, cacheKey)
-		if err != nil {
-			log.Error().Err(err).Send()
-			return
-		}
-
-		raw, err = cmd.CLI.Encode(item.ID, raw)
-		if err != nil {
-			log.Error().Err(err).Send()
-			return
-		}
-
-		if err := vc.Speaking(true); err != nil {
-			log.Error().Err(fmt.Errorf("failed to start speaking: %s", err)).Send()
-			return
-		}
-
-		defer func() {
-			if err := vc.Speaking(false); err != nil {
-				log.Error().Err(fmt.Errorf("failed to stop speaking: %s", err)).Send()
-			}
-		}()
-
-		buffer := bytes.NewBuffer(raw)
-
-		for {
-			var frameLength int16
-			if err := binary.Read(buffer, binary.LittleEndian, &frameLength); err != nil {
-				log.Error().Err(fmt.Errorf("failed to read frame length: %s", err)).Send()
-				return
-			}
-
-			frame := make([]byte, frameLength)
-			if err := binary.Read(buffer, binary.LittleEndian, &frame); err != nil {
-				if err != io.EOF && err != io.ErrUnexpectedEOF {
-					log.Error().Err(fmt.Errorf("failed to read frame: %s", err)).Send()
-				}
-
-				return
-			}
-
-			vc.OpusSend <- frame
-		}
-
-		// TODO enqueue
-	}()
+func isAudioAttachment(attachment *discordgo.MessageAttachment) bool {
+	return strings.HasPrefix(attachment.ContentType, "audio/") || source.IsAudioFileName(attachment.Filename)
 }
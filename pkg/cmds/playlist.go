@@ -0,0 +1,557 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/axatol/guosheng/pkg/playlists"
+	"github.com/axatol/guosheng/pkg/util"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Playlist)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Playlist)(nil)
+	_ discord.MessageComponentInteractionHandler   = (*Playlist)(nil)
+)
+
+type Playlist struct {
+	Players   *player.Manager
+	Playlists *playlists.Store
+}
+
+func (cmd Playlist) Name() string {
+	return "playlist"
+}
+
+func (cmd Playlist) Description() string {
+	return "Save and play your own playlists"
+}
+
+// reply is either plain content or a page of a playlist
+type playlistReply struct {
+	content    string
+	embed      *discordgo.MessageEmbed
+	components []discordgo.MessageComponent
+}
+
+func playlistContent(content string) playlistReply {
+	return playlistReply{content: content}
+}
+
+func playlistError(err error) playlistReply {
+	switch {
+	case errors.Is(err, playlists.ErrPlaylistNotFound),
+		errors.Is(err, playlists.ErrPlaylistExists),
+		errors.Is(err, playlists.ErrNotOwner):
+	default:
+		log.Warn().Err(err).Send()
+	}
+
+	return playlistContent(err.Error())
+}
+
+// snapshotTracks copies the tracks without any details of the current request
+func snapshotTracks(tracks ...*player.Track) []*player.Track {
+	result := make([]*player.Track, 0, len(tracks))
+	for _, track := range tracks {
+		if track == nil {
+			continue
+		}
+
+		copied := *track
+		copied.RequesterID = ""
+		copied.Autoplay = false
+		copied.Chapters = nil
+		result = append(result, &copied)
+	}
+
+	return result
+}
+
+func (cmd Playlist) create(ctx context.Context, guildID, userID, name string, shared, fromQueue bool) playlistReply {
+	var tracks []*player.Track
+	if fromQueue {
+		guildPlayer, ok := cmd.Players.Lookup(guildID)
+		if !ok || guildPlayer.Current() == nil {
+			return playlistContent("nothing is playing")
+		}
+
+		tracks = snapshotTracks(append([]*player.Track{guildPlayer.Current()}, guildPlayer.Queue()...)...)
+	}
+
+	playlist, err := cmd.Playlists.Create(ctx, guildID, userID, name, shared, tracks)
+	if err != nil {
+		return playlistError(err)
+	}
+
+	return playlistContent(fmt.Sprintf("created playlist **%s** with %d tracks", playlist.Name, len(playlist.Tracks)))
+}
+
+func (cmd Playlist) add(ctx context.Context, guildID, userID, name, input string) playlistReply {
+	var tracks []*player.Track
+	if input == "" {
+		guildPlayer, ok := cmd.Players.Lookup(guildID)
+		if !ok || guildPlayer.Current() == nil {
+			return playlistContent("nothing is playing")
+		}
+
+		tracks = snapshotTracks(guildPlayer.Current())
+	} else {
+		resolved, err := cmd.Players.Resolve(ctx, input)
+		if err != nil || len(resolved) < 1 {
+			log.Warn().Err(err).Send()
+			return playlistContent("could not find that")
+		}
+
+		tracks = snapshotTracks(resolved...)
+	}
+
+	playlist, err := cmd.Playlists.Update(ctx, guildID, userID, name, func(playlist *playlists.Playlist) error {
+		playlist.Tracks = append(playlist.Tracks, tracks...)
+		return nil
+	})
+
+	if err != nil {
+		return playlistError(err)
+	}
+
+	if len(tracks) == 1 {
+		return playlistContent(fmt.Sprintf("added **%s** to **%s** at position %d", tracks[0].Title, playlist.Name, len(playlist.Tracks)))
+	}
+
+	return playlistContent(fmt.Sprintf("added %d tracks to **%s**", len(tracks), playlist.Name))
+}
+
+func (cmd Playlist) remove(ctx context.Context, guildID, userID, name string, position int) playlistReply {
+	var removed *player.Track
+	playlist, err := cmd.Playlists.Update(ctx, guildID, userID, name, func(playlist *playlists.Playlist) error {
+		if position < 1 || position > len(playlist.Tracks) {
+			return fmt.Errorf("position must be between 1 and %d", len(playlist.Tracks))
+		}
+
+		removed = playlist.Tracks[position-1]
+		playlist.Tracks = append(playlist.Tracks[:position-1], playlist.Tracks[position:]...)
+		return nil
+	})
+
+	if err != nil {
+		return playlistError(err)
+	}
+
+	return playlistContent(fmt.Sprintf("removed **%s** from **%s**", removed.Title, playlist.Name))
+}
+
+func (cmd Playlist) delete(ctx context.Context, guildID, userID, name string) playlistReply {
+	if err := cmd.Playlists.Delete(ctx, guildID, userID, name); err != nil {
+		return playlistError(err)
+	}
+
+	return playlistContent(fmt.Sprintf("deleted playlist **%s**", name))
+}
+
+func (cmd Playlist) list(ctx context.Context, guildID, userID string) playlistReply {
+	all, err := cmd.Playlists.List(ctx, guildID, userID)
+	if err != nil {
+		return playlistError(err)
+	}
+
+	if len(all) < 1 {
+		return playlistContent("no playlists saved yet")
+	}
+
+	embed := discord.NewMessageEmbed().SetTitle("Playlists")
+	for _, playlist := range all {
+		details := fmt.Sprintf("%d tracks, %s", len(playlist.Tracks), util.FormatDuration(playlist.Duration()))
+		if playlist.Shared() {
+			details = fmt.Sprintf("%s, shared by <@%s>", details, playlist.OwnerID)
+		}
+
+		embed.AddField(playlist.Name, details, false)
+	}
+
+	return playlistReply{embed: embed.Embed()}
+}
+
+func (cmd Playlist) show(ctx context.Context, guildID, userID, name string, page int) playlistReply {
+	playlist, err := cmd.Playlists.Get(ctx, guildID, userID, name)
+	if err != nil {
+		return playlistError(err)
+	}
+
+	return cmd.page(playlist, page)
+}
+
+// page renders the tracks on a page of the playlist along with buttons to
+// move between pages when there is more than one
+func (cmd Playlist) page(playlist *playlists.Playlist, page int) playlistReply {
+	page = min(max(page, 0), playlist.Pages()-1)
+
+	embed := discord.NewMessageEmbed().
+		SetTitle(playlist.Name).
+		SetDescription(fmt.Sprintf("%d tracks, %s", len(playlist.Tracks), util.FormatDuration(playlist.Duration()))).
+		SetFooter(fmt.Sprintf("page %d/%d", page+1, playlist.Pages()))
+
+	for i, track := range playlist.Page(page) {
+		position := page*playlists.PageSize + i + 1
+		embed.AddField(fmt.Sprintf("%d. %s", position, truncate(track.Title, 200)), fmt.Sprintf("%s, %s", util.MDLink(track.Uploader, track.UploaderURL), track.DurationString()), false)
+	}
+
+	reply := playlistReply{embed: embed.Embed()}
+	if playlist.Pages() > 1 {
+		reply.components = []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{Label: "Previous", Emoji: discordgo.ComponentEmoji{Name: "◀️"}, Style: discordgo.SecondaryButton, CustomID: cmd.pageCustomID(playlist, page-1), Disabled: page < 1},
+					discordgo.Button{Label: "Next", Emoji: discordgo.ComponentEmoji{Name: "▶️"}, Style: discordgo.SecondaryButton, CustomID: cmd.pageCustomID(playlist, page+1), Disabled: page >= playlist.Pages()-1},
+				},
+			},
+		}
+	}
+
+	return reply
+}
+
+// pageCustomID encodes where the playlist is stored so any user can page
+// through it, in the format "playlist:<g|u>/<id>/<page>/<name>"
+func (cmd Playlist) pageCustomID(playlist *playlists.Playlist, page int) string {
+	scope, id := "u", playlist.OwnerID
+	if playlist.Shared() {
+		scope, id = "g", playlist.GuildID
+	}
+
+	return fmt.Sprintf("%s:%s/%s/%d/%s", cmd.Name(), scope, id, page, playlist.Name)
+}
+
+func parsePageCustomID(customID string) (scope, id string, page int, name string, err error) {
+	parts := strings.SplitN(customID, "/", 4)
+	if len(parts) != 4 {
+		return "", "", 0, "", fmt.Errorf("invalid playlist page custom id %s", customID)
+	}
+
+	page, err = strconv.Atoi(parts[2])
+	if err != nil {
+		return "", "", 0, "", fmt.Errorf("invalid playlist page %s: %s", parts[2], err)
+	}
+
+	return parts[0], parts[1], page, parts[3], nil
+}
+
+func (cmd Playlist) play(ctx context.Context, guildID, channelID, textChannelID string, user *discordgo.User, name string) playlistReply {
+	playlist, err := cmd.Playlists.Get(ctx, guildID, user.ID, name)
+	if err != nil {
+		return playlistError(err)
+	}
+
+	if len(playlist.Tracks) < 1 {
+		return playlistContent(fmt.Sprintf("**%s** has no tracks", playlist.Name))
+	}
+
+	tracks := snapshotTracks(playlist.Tracks...)
+	for _, track := range tracks {
+		track.RequesterID = user.ID
+	}
+
+	guildPlayer := cmd.Players.Get(guildID)
+	guildPlayer.SetTextChannel(textChannelID)
+	position := guildPlayer.Enqueue(channelID, tracks...)
+
+	embed := discord.NewMessageEmbed().
+		SetTitle(playlist.Name).
+		AddField("Added", fmt.Sprint(len(tracks))).
+		AddField("Duration", util.FormatDuration(playlist.Duration()))
+
+	if position > 0 {
+		embed.AddField("Position", fmt.Sprint(position))
+	}
+
+	return playlistReply{embed: embed.Embed()}
+}
+
+// playlistNameArgs joins the name back together, picking out the shared flag
+// to match the option of the slash command
+func playlistNameArgs(args []string) (string, bool) {
+	var name []string
+	shared := false
+	for _, arg := range args {
+		if arg == "--shared" {
+			shared = true
+			continue
+		}
+
+		name = append(name, arg)
+	}
+
+	return strings.Join(name, " "), shared
+}
+
+func (cmd Playlist) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	userID := event.Author.ID
+	usage := fmt.Sprintf("usage: `%s%s [create [--shared] <name> | save [--shared] <name> | add <name> [url] | remove <name> <position> | show <name> [page] | play <name> | delete <name>]`", bot.MessagePrefix, cmd.Name())
+
+	// names may contain spaces so the url, position or page is always last
+	var reply playlistReply
+	switch {
+	case event.GuildID == "":
+		reply = playlistContent("playlists can only be used in a server")
+	case len(args) < 1:
+		reply = cmd.list(ctx, event.GuildID, userID)
+	case (args[0] == "create" || args[0] == "save") && len(args) > 1:
+		name, shared := playlistNameArgs(args[1:])
+		if name == "" {
+			reply = playlistContent(usage)
+			break
+		}
+
+		reply = cmd.create(ctx, event.GuildID, userID, name, shared, args[0] == "save")
+	case args[0] == "add" && len(args) > 1:
+		name, input := strings.Join(args[1:], " "), ""
+		if last := args[len(args)-1]; len(args) > 2 && strings.Contains(last, "://") {
+			name, input = strings.Join(args[1:len(args)-1], " "), last
+		}
+
+		reply = cmd.add(ctx, event.GuildID, userID, name, input)
+	case args[0] == "remove" && len(args) > 2:
+		position, err := strconv.Atoi(args[len(args)-1])
+		if err != nil {
+			reply = playlistContent(fmt.Sprintf("invalid position %s", args[len(args)-1]))
+			break
+		}
+
+		reply = cmd.remove(ctx, event.GuildID, userID, strings.Join(args[1:len(args)-1], " "), position)
+	case args[0] == "show" && len(args) > 1:
+		name, page := strings.Join(args[1:], " "), 1
+		if parsed, err := strconv.Atoi(args[len(args)-1]); err == nil && len(args) > 2 {
+			name, page = strings.Join(args[1:len(args)-1], " "), parsed
+		}
+
+		reply = cmd.show(ctx, event.GuildID, userID, name, page-1)
+	case args[0] == "play" && len(args) > 1:
+		guildID, channelID := bot.GetUserVoiceChannel(userID)
+		if guildID == "" || channelID == "" {
+			reply = playlistContent("must be in a voice channel")
+			break
+		}
+
+		reply = cmd.play(ctx, guildID, channelID, event.ChannelID, event.Author, strings.Join(args[1:], " "))
+	case args[0] == "delete" && len(args) > 1:
+		reply = cmd.delete(ctx, event.GuildID, userID, strings.Join(args[1:], " "))
+	default:
+		reply = playlistContent(usage)
+	}
+
+	send := discordgo.MessageSend{
+		Content:    reply.content,
+		Components: reply.components,
+		Reference:  event.Message.Reference(),
+	}
+
+	if reply.embed != nil {
+		send.Embeds = []*discordgo.MessageEmbed{reply.embed}
+	}
+
+	if _, err := bot.Session.ChannelMessageSendComplex(event.ChannelID, &send, discord.RequestOptions(ctx)); err != nil {
+		log.Warn().Err(fmt.Errorf("failed to respond to message %s: %s", event.Message.ID, err)).Send()
+	}
+}
+
+func (cmd Playlist) ApplicationCommand() *discordgo.ApplicationCommand {
+	nameOption := discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "name",
+		Description: "playlist name",
+		Required:    true,
+		MaxLength:   32,
+	}
+
+	minPosition := float64(1)
+	// playlists belong to a guild as well as a user
+	dmPermission := false
+
+	return &discordgo.ApplicationCommand{
+		Name:         cmd.Name(),
+		Description:  cmd.Description(),
+		DMPermission: &dmPermission,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "create",
+				Description: "Create a playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					&nameOption,
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "shared",
+						Description: "share the playlist with everyone in the server",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "from_queue",
+						Description: "start with the current song and queue",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add a song to a playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					&nameOption,
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "url",
+						Description: "link to add, defaults to the current song",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove a song from a playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					&nameOption,
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "position",
+						Description: "position of the song in the playlist",
+						Required:    true,
+						MinValue:    &minPosition,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "show",
+				Description: "List playlists or the songs in one",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "playlist name, omit to list all playlists",
+						MaxLength:   32,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "page",
+						Description: "page to show",
+						MinValue:    &minPosition,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "play",
+				Description: "Queue every song in a playlist",
+				Options:     []*discordgo.ApplicationCommandOption{&nameOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "delete",
+				Description: "Delete a playlist",
+				Options:     []*discordgo.ApplicationCommandOption{&nameOption},
+			},
+		},
+	}
+}
+
+func (cmd Playlist) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	user := interactionUser(event)
+	opts := resolveOptions(data.Options)
+
+	var reply playlistReply
+	if event.GuildID == "" {
+		reply = playlistContent("playlists can only be used in a server")
+	} else if create, ok := opts["create"].(map[string]any); ok {
+		name, _ := create["name"].(string)
+		shared, _ := create["shared"].(bool)
+		fromQueue, _ := create["from_queue"].(bool)
+		reply = cmd.create(ctx, event.GuildID, user.ID, name, shared, fromQueue)
+	} else if add, ok := opts["add"].(map[string]any); ok {
+		name, _ := add["name"].(string)
+		input, _ := add["url"].(string)
+		reply = cmd.add(ctx, event.GuildID, user.ID, name, input)
+	} else if remove, ok := opts["remove"].(map[string]any); ok {
+		name, _ := remove["name"].(string)
+		position, _ := remove["position"].(int64)
+		reply = cmd.remove(ctx, event.GuildID, user.ID, name, int(position))
+	} else if play, ok := opts["play"].(map[string]any); ok {
+		name, _ := play["name"].(string)
+		guildID, channelID := bot.GetUserVoiceChannel(user.ID)
+		if guildID == "" || channelID == "" {
+			reply = playlistContent("must be in a voice channel")
+		} else {
+			reply = cmd.play(ctx, guildID, channelID, event.ChannelID, user, name)
+		}
+	} else if del, ok := opts["delete"].(map[string]any); ok {
+		name, _ := del["name"].(string)
+		reply = cmd.delete(ctx, event.GuildID, user.ID, name)
+	} else if show, ok := opts["show"].(map[string]any); ok && show["name"] != nil {
+		name, _ := show["name"].(string)
+		page, ok := show["page"].(int64)
+		if !ok {
+			page = 1
+		}
+
+		reply = cmd.show(ctx, event.GuildID, user.ID, name, int(page)-1)
+	} else {
+		reply = cmd.list(ctx, event.GuildID, user.ID)
+	}
+
+	response := discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:    reply.content,
+			Components: reply.components,
+		},
+	}
+
+	if reply.embed != nil {
+		response.Data.Embeds = []*discordgo.MessageEmbed{reply.embed}
+	}
+
+	if err := bot.SendInteractionReply(ctx, event.Interaction, &response); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Playlist) OnMessageComponent(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.MessageComponentInteractionData) {
+	customID := strings.Split(data.CustomID, ":")[1]
+	log := log.With().Str("custom_id", customID).Logger()
+
+	var reply playlistReply
+	if scope, id, page, name, err := parsePageCustomID(customID); err != nil {
+		log.Debug().Err(err).Send()
+		reply = playlistContent("invalid page")
+	} else if playlist, err := cmd.Playlists.Lookup(ctx, scope == "g", id, name); err != nil {
+		reply = playlistError(err)
+	} else {
+		reply = cmd.page(playlist, page)
+	}
+
+	response := discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    reply.content,
+			Components: reply.components,
+			Embeds:     []*discordgo.MessageEmbed{},
+		},
+	}
+
+	if reply.embed != nil {
+		response.Data.Embeds = []*discordgo.MessageEmbed{reply.embed}
+	}
+
+	if reply.components == nil {
+		response.Data.Components = []discordgo.MessageComponent{}
+	}
+
+	if err := bot.SendInteractionReply(ctx, event.Interaction, &response); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
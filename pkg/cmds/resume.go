@@ -0,0 +1,58 @@
+package cmds
+
+import (
+	"context"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Resume)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Resume)(nil)
+	_ discord.PermissionedCommand                  = (*Resume)(nil)
+)
+
+type Resume struct{ Players *player.Manager }
+
+func (cmd Resume) Name() string {
+	return "resume"
+}
+
+func (cmd Resume) Description() string {
+	return "Resume the current song"
+}
+
+func (cmd Resume) Permission() discord.Permission {
+	return discord.PermissionDJ
+}
+
+func (cmd Resume) run(guildID string) string {
+	guildPlayer, ok := cmd.Players.Lookup(guildID)
+	if !ok || !guildPlayer.Resume() {
+		return "nothing is paused"
+	}
+
+	return "▶️"
+}
+
+func (cmd Resume) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	if err := bot.SendMessageReply(ctx, event.Message, cmd.run(event.GuildID)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Resume) ApplicationCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+	}
+}
+
+func (cmd Resume) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, cmd.run(event.GuildID)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
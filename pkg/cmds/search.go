@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
 	"github.com/axatol/guosheng/pkg/yt"
 	"github.com/bwmarrin/discordgo"
 	"github.com/rs/zerolog/log"
@@ -16,7 +17,10 @@ var (
 	_ discord.MessageComponentInteractionHandler   = (*Search)(nil)
 )
 
-type Search struct{ YouTube *yt.Client }
+type Search struct {
+	YouTube *yt.Client
+	Players *player.Manager
+}
 
 func (cmd Search) Name() string {
 	return "search"
@@ -124,9 +128,29 @@ func (cmd Search) OnMessageComponent(ctx context.Context, bot *discord.Bot, even
 		log.Warn().Err(err).Send()
 	}
 
+	user := interactionUser(event)
+	guildID, channelID := bot.GetUserVoiceChannel(user.ID)
+	if guildID == "" || channelID == "" {
+		content := "must be in a voice channel"
+		edit := discordgo.WebhookEdit{Content: &content}
+		if err := bot.SendInteractionEdit(ctx, event.Interaction, &edit); err != nil {
+			log.Warn().Err(err).Send()
+		}
+
+		return
+	}
+
+	guildPlayer := cmd.Players.Get(guildID)
+	guildPlayer.SetTextChannel(event.ChannelID)
 	embeds := make([]*discordgo.MessageEmbed, len(results))
 	for i, result := range results {
-		embeds[i] = result.AsMessageEmbed()
+		track := newTrackFromVideo(&result, user)
+		embed := track.MessageEmbed()
+		if position := guildPlayer.Enqueue(channelID, track); position > 0 {
+			embed.AddField("Position", fmt.Sprint(position))
+		}
+
+		embeds[i] = embed.Embed()
 	}
 
 	edit := discordgo.WebhookEdit{
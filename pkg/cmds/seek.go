@@ -0,0 +1,80 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/axatol/guosheng/pkg/util"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Seek)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Seek)(nil)
+	_ discord.PermissionedCommand                  = (*Seek)(nil)
+)
+
+type Seek struct{ Players *player.Manager }
+
+func (cmd Seek) Name() string {
+	return "seek"
+}
+
+func (cmd Seek) Description() string {
+	return "Jump to a position in the current song"
+}
+
+func (cmd Seek) Permission() discord.Permission {
+	return discord.PermissionDJ
+}
+
+func (cmd Seek) run(guildID, input string) string {
+	position, err := util.ParseTimestamp(input)
+	if err != nil {
+		return fmt.Sprintf("invalid position, try something like 1:30 or 90: %s", err)
+	}
+
+	guildPlayer, ok := cmd.Players.Lookup(guildID)
+	if ok {
+		if current := guildPlayer.Current(); current != nil && current.Live {
+			return "cannot seek a live stream"
+		}
+	}
+
+	if !ok || !guildPlayer.Seek(position) {
+		return "nothing is playing"
+	}
+
+	return fmt.Sprintf("⏩ %s", util.FormatDuration(position))
+}
+
+func (cmd Seek) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	if err := bot.SendMessageReply(ctx, event.Message, cmd.run(event.GuildID, strings.Join(args, ""))); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Seek) ApplicationCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+		Options: []*discordgo.ApplicationCommandOption{{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "position",
+			Description: "position to jump to, e.g. 1:30 or 90",
+			Required:    true,
+		}},
+	}
+}
+
+func (cmd Seek) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	opts := resolveOptions(data.Options)
+	position, _ := opts["position"].(string)
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, cmd.run(event.GuildID, position)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
@@ -0,0 +1,120 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Segments)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Segments)(nil)
+	_ discord.PermissionedCommand                  = (*Segments)(nil)
+)
+
+type Segments struct{ Players *player.Manager }
+
+func (cmd Segments) Name() string {
+	return "segments"
+}
+
+func (cmd Segments) Description() string {
+	return "Choose which parts of videos to skip, such as sponsors or intros"
+}
+
+func (cmd Segments) Permission() discord.Permission {
+	return discord.PermissionDJ
+}
+
+func (cmd Segments) run(ctx context.Context, guildID, category string, enabled bool) string {
+	if guildID == "" {
+		return guildOnlyReply
+	}
+
+	if category != "" {
+		if err := cmd.Players.Get(guildID).SetSkipCategory(ctx, category, enabled); err != nil {
+			log.Warn().Err(err).Send()
+			return err.Error()
+		}
+	}
+
+	// listing alone does not need a player
+	settings, err := cmd.Players.Settings(ctx, guildID)
+	if err != nil {
+		log.Warn().Err(err).Send()
+		return "could not load segment settings"
+	}
+
+	skipped := settings.SkipCategories
+	lines := make([]string, len(player.SegmentCategories))
+	for i, category := range player.SegmentCategories {
+		status := "▶️"
+		if slices.Contains(skipped, category) {
+			status = "⏭️"
+		}
+
+		lines[i] = fmt.Sprintf("%s `%s`", status, category)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (cmd Segments) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	var reply string
+	switch {
+	case len(args) < 1:
+		reply = cmd.run(ctx, event.GuildID, "", false)
+	case len(args) == 2 && (args[1] == "on" || args[1] == "off"):
+		reply = cmd.run(ctx, event.GuildID, args[0], args[1] == "on")
+	default:
+		reply = fmt.Sprintf("usage: `%s%s [<category> on|off]`", bot.MessagePrefix, cmd.Name())
+	}
+
+	if err := bot.SendMessageReply(ctx, event.Message, reply); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Segments) ApplicationCommand() *discordgo.ApplicationCommand {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(player.SegmentCategories))
+	for i, category := range player.SegmentCategories {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{Name: category, Value: category}
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "category",
+				Description: "the kind of segment, lists the categories if omitted",
+				Choices:     choices,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "skip",
+				Description: "whether to skip the category, defaults to true",
+			},
+		},
+	}
+}
+
+func (cmd Segments) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	opts := resolveOptions(data.Options)
+	category, _ := opts["category"].(string)
+	skip, ok := opts["skip"].(bool)
+	if !ok {
+		skip = true
+	}
+
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, cmd.run(ctx, event.GuildID, category, skip)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
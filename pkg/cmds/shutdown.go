@@ -10,6 +10,7 @@ import (
 
 var (
 	_ discord.ApplicationCommandInteractionHandler = (*Shutdown)(nil)
+	_ discord.PermissionedCommand                  = (*Shutdown)(nil)
 )
 
 type Shutdown struct{ Shutdown func() }
@@ -22,10 +23,17 @@ func (cmd Shutdown) Description() string {
 	return "shutdown the bot"
 }
 
+func (cmd Shutdown) Permission() discord.Permission {
+	return discord.PermissionOwner
+}
+
 func (cmd Shutdown) ApplicationCommand() *discordgo.ApplicationCommand {
+	// hidden from everyone but administrators, the owner check happens on use
+	permissions := int64(discordgo.PermissionAdministrator)
 	return &discordgo.ApplicationCommand{
-		Name:        cmd.Name(),
-		Description: cmd.Description(),
+		Name:                     cmd.Name(),
+		Description:              cmd.Description(),
+		DefaultMemberPermissions: &permissions,
 	}
 }
 
@@ -0,0 +1,71 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Skip)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Skip)(nil)
+)
+
+type Skip struct{ Players *player.Manager }
+
+func (cmd Skip) Name() string {
+	return "skip"
+}
+
+func (cmd Skip) Description() string {
+	return "Skip the current song"
+}
+
+func (cmd Skip) run(ctx context.Context, bot *discord.Bot, guildID, channelID string, user *discordgo.User, member *discordgo.Member) string {
+	guildPlayer, ok := cmd.Players.Lookup(guildID)
+	if !ok {
+		return "nothing is playing"
+	}
+
+	vote, err := voteSkip(ctx, bot, guildPlayer, channelID, user, member)
+	if err != nil {
+		return err.Error()
+	}
+
+	if !vote.Skipped {
+		return fmt.Sprintf("🗳️ %d/%d votes to skip", vote.Votes, vote.Needed)
+	}
+
+	return "⏭️"
+}
+
+// voteSkip puts the skip to a vote, djs skip straight away
+func voteSkip(ctx context.Context, bot *discord.Bot, guildPlayer *player.Player, channelID string, user *discordgo.User, member *discordgo.Member) (*player.SkipVote, error) {
+	listeners := bot.GetVoiceChannelListeners(guildPlayer.GuildID(), bot.GetBotVoiceChannel(guildPlayer.GuildID()))
+	dj := bot.IsDJ(guildPlayer.GuildID(), channelID, user, member)
+	return guildPlayer.VoteSkip(ctx, user.ID, listeners, dj)
+}
+
+func (cmd Skip) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	if err := bot.SendMessageReply(ctx, event.Message, cmd.run(ctx, bot, event.GuildID, event.ChannelID, event.Author, event.Member)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Skip) ApplicationCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+	}
+}
+
+func (cmd Skip) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	reply := cmd.run(ctx, bot, event.GuildID, event.ChannelID, interactionUser(event), event.Member)
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, reply); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
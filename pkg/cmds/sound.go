@@ -0,0 +1,108 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/soundboard"
+	"github.com/axatol/guosheng/pkg/util"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Sound)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Sound)(nil)
+)
+
+type Sound struct{ Soundboard *soundboard.Soundboard }
+
+func (cmd Sound) Name() string {
+	return "sound"
+}
+
+func (cmd Sound) Description() string {
+	return "Play a sound from the soundboard"
+}
+
+func (cmd Sound) run(ctx context.Context, bot *discord.Bot, userID, name string) string {
+	guildID, channelID := bot.GetUserVoiceChannel(userID)
+	if guildID == "" || channelID == "" {
+		return "must be in a voice channel"
+	}
+
+	clip, err := cmd.Soundboard.Play(ctx, guildID, channelID, name)
+	if err != nil {
+		if !errors.Is(err, soundboard.ErrClipNotFound) {
+			log.Warn().Err(err).Send()
+		}
+
+		return err.Error()
+	}
+
+	return fmt.Sprintf("🔊 %s", clip.Name)
+}
+
+func listSounds(ctx context.Context, board *soundboard.Soundboard, guildID string) string {
+	clips, err := board.List(ctx, guildID)
+	if err != nil {
+		log.Warn().Err(err).Send()
+		return "could not load sounds"
+	}
+
+	if len(clips) < 1 {
+		return "no sounds added yet"
+	}
+
+	lines := make([]string, len(clips))
+	for i, clip := range clips {
+		lines[i] = fmt.Sprintf("`%s` - %s from %s", clip.Name, util.FormatDuration(clip.Duration), util.MDLink(clip.Title, clip.URL))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (cmd Sound) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	var reply string
+	if len(args) < 1 {
+		reply = listSounds(ctx, cmd.Soundboard, event.GuildID)
+	} else {
+		reply = cmd.run(ctx, bot, event.Author.ID, args[0])
+	}
+
+	if err := bot.SendMessageReply(ctx, event.Message, reply); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Sound) ApplicationCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+		Options: []*discordgo.ApplicationCommandOption{{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "name",
+			Description: "sound to play, omit to list them",
+			MaxLength:   32,
+		}},
+	}
+}
+
+func (cmd Sound) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	opts := resolveOptions(data.Options)
+	name, _ := opts["name"].(string)
+
+	var reply string
+	if name == "" {
+		reply = listSounds(ctx, cmd.Soundboard, event.GuildID)
+	} else {
+		reply = cmd.run(ctx, bot, interactionUser(event).ID, name)
+	}
+
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, reply); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
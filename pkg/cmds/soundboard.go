@@ -0,0 +1,227 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/soundboard"
+	"github.com/axatol/guosheng/pkg/util"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Soundboard)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Soundboard)(nil)
+	_ discord.PermissionedCommand                  = (*Soundboard)(nil)
+)
+
+type Soundboard struct{ Soundboard *soundboard.Soundboard }
+
+func (cmd Soundboard) Name() string {
+	return "soundboard"
+}
+
+func (cmd Soundboard) Description() string {
+	return "Add or remove soundboard sounds"
+}
+
+// Permission is not left to the dj role, which lets everyone through when
+// there is none
+func (cmd Soundboard) Permission() discord.Permission {
+	return discord.PermissionAdmin
+}
+
+// add encodes the clip, which can take a while for long videos, errors are
+// meant to be shown to the user
+func (cmd Soundboard) add(ctx context.Context, guildID, userID, name, input, start, end string) string {
+	var startAt, endAt time.Duration
+	if start != "" {
+		parsed, err := util.ParseTimestamp(start)
+		if err != nil {
+			return fmt.Sprintf("invalid start, try something like 1:30 or 90: %s", err)
+		}
+
+		startAt = parsed
+	}
+
+	if end != "" {
+		parsed, err := util.ParseTimestamp(end)
+		if err != nil {
+			return fmt.Sprintf("invalid end, try something like 1:30 or 90: %s", err)
+		}
+
+		endAt = parsed
+	}
+
+	clip, err := cmd.Soundboard.Add(ctx, guildID, userID, name, input, startAt, endAt)
+	if err != nil {
+		if !errors.Is(err, soundboard.ErrClipExists) {
+			log.Warn().Err(err).Send()
+		}
+
+		return err.Error()
+	}
+
+	return fmt.Sprintf("added `%s`, %s from %s", clip.Name, util.FormatDuration(clip.Duration), util.MDLink(clip.Title, clip.URL))
+}
+
+func (cmd Soundboard) remove(ctx context.Context, guildID, name string) string {
+	if err := cmd.Soundboard.Remove(ctx, guildID, name); err != nil {
+		if !errors.Is(err, soundboard.ErrClipNotFound) {
+			log.Warn().Err(err).Send()
+		}
+
+		return err.Error()
+	}
+
+	return fmt.Sprintf("removed `%s`", name)
+}
+
+func (cmd Soundboard) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	var reply string
+	switch {
+	case len(args) < 1 || args[0] == "list":
+		reply = listSounds(ctx, cmd.Soundboard, event.GuildID)
+	case args[0] == "add" && len(args) > 2:
+		var start, end string
+		if len(args) > 3 {
+			start = args[3]
+		}
+
+		if len(args) > 4 {
+			end = args[4]
+		}
+
+		reply = cmd.add(ctx, event.GuildID, event.Author.ID, args[1], args[2], start, end)
+	case args[0] == "add" && len(args) == 2 && len(event.Message.Attachments) > 0:
+		reply = cmd.add(ctx, event.GuildID, event.Author.ID, args[1], event.Message.Attachments[0].URL, "", "")
+	case args[0] == "remove" && len(args) > 1:
+		reply = cmd.remove(ctx, event.GuildID, args[1])
+	default:
+		reply = fmt.Sprintf("usage: `%s%s [list | add <name> <url> [start] [end] | remove <name>]`", bot.MessagePrefix, cmd.Name())
+	}
+
+	if err := bot.SendMessageReply(ctx, event.Message, reply); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Soundboard) ApplicationCommand() *discordgo.ApplicationCommand {
+	// hidden from members who cannot manage the server unless granted
+	permissions := int64(discordgo.PermissionManageServer)
+	nameOption := discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "name",
+		Description: "sound name",
+		Required:    true,
+		MaxLength:   32,
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:                     cmd.Name(),
+		Description:              cmd.Description(),
+		DefaultMemberPermissions: &permissions,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add a sound from a link, video or audio file",
+				Options: []*discordgo.ApplicationCommandOption{
+					&nameOption,
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "url",
+						Description: "link to take the sound from",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "video_id",
+						Description: "youtube video id to take the sound from",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionAttachment,
+						Name:        "attachment",
+						Description: "audio file to take the sound from",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "start",
+						Description: "where the sound starts, such as 1:30 or 90",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "end",
+						Description: "where the sound ends, defaults to the longest allowed",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove a sound",
+				Options:     []*discordgo.ApplicationCommandOption{&nameOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List the sounds",
+			},
+		},
+	}
+}
+
+func (cmd Soundboard) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	opts := resolveOptions(data.Options)
+
+	add, ok := opts["add"].(map[string]any)
+	if !ok {
+		var reply string
+		if remove, ok := opts["remove"].(map[string]any); ok {
+			name, _ := remove["name"].(string)
+			reply = cmd.remove(ctx, event.GuildID, name)
+		} else {
+			reply = listSounds(ctx, cmd.Soundboard, event.GuildID)
+		}
+
+		if err := bot.SendInteractionMessageReply(ctx, event.Interaction, reply); err != nil {
+			log.Warn().Err(err).Send()
+		}
+
+		return
+	}
+
+	name, _ := add["name"].(string)
+	start, _ := add["start"].(string)
+	end, _ := add["end"].(string)
+	input, ok := add["video_id"].(string)
+	if !ok {
+		input, _ = add["url"].(string)
+	}
+
+	if attachmentID, ok := add["attachment"].(string); ok && input == "" && data.Resolved != nil {
+		if attachment, ok := data.Resolved.Attachments[attachmentID]; ok {
+			input = attachment.URL
+		}
+	}
+
+	if input == "" {
+		if err := bot.SendInteractionMessageReply(ctx, event.Interaction, "must provide a url, video id or attachment"); err != nil {
+			log.Warn().Err(err).Send()
+		}
+
+		return
+	}
+
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, "🤔"); err != nil {
+		log.Warn().Err(err).Send()
+	}
+
+	reply := cmd.add(ctx, event.GuildID, interactionUser(event).ID, name, input, start, end)
+	if err := bot.SendInteractionEdit(ctx, event.Interaction, &discordgo.WebhookEdit{Content: &reply}); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
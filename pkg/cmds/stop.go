@@ -0,0 +1,62 @@
+package cmds
+
+import (
+	"context"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Stop)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Stop)(nil)
+	_ discord.PermissionedCommand                  = (*Stop)(nil)
+)
+
+type Stop struct{ Players *player.Manager }
+
+func (cmd Stop) Name() string {
+	return "stop"
+}
+
+func (cmd Stop) Description() string {
+	return "Stop playing and clear the queue"
+}
+
+func (cmd Stop) Permission() discord.Permission {
+	return discord.PermissionDJ
+}
+
+func (cmd Stop) run(guildID string) string {
+	guildPlayer, ok := cmd.Players.Lookup(guildID)
+	if !ok {
+		return "nothing is playing"
+	}
+
+	if err := guildPlayer.Stop(); err != nil {
+		log.Warn().Err(err).Send()
+	}
+
+	return "⏹️"
+}
+
+func (cmd Stop) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	if err := bot.SendMessageReply(ctx, event.Message, cmd.run(event.GuildID)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Stop) ApplicationCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+	}
+}
+
+func (cmd Stop) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, cmd.run(event.GuildID)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
@@ -1,6 +1,13 @@
 package cmds
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/axatol/guosheng/pkg/yt"
+	"github.com/bwmarrin/discordgo"
+)
+
+// settings and players belong to a guild, there is none in direct messages
+const guildOnlyReply = "commands must be used in a server"
 
 func resolveOptions(opts []*discordgo.ApplicationCommandInteractionDataOption) map[string]any {
 	result := map[string]any{}
@@ -17,8 +24,36 @@ func resolveOptions(opts []*discordgo.ApplicationCommandInteractionDataOption) m
 			result[opt.Name] = resolveOptions(opt.Options)
 		case discordgo.ApplicationCommandOptionNumber:
 			result[opt.Name] = opt.FloatValue()
+		case discordgo.ApplicationCommandOptionAttachment:
+			// the attachment itself is in the resolved data of the interaction
+			result[opt.Name] = opt.Value
 		}
 	}
 
 	return result
 }
+
+func interactionUser(event *discordgo.InteractionCreate) *discordgo.User {
+	if event.Member != nil && event.Member.User != nil {
+		return event.Member.User
+	}
+
+	return event.User
+}
+
+func newTrackFromVideo(video *yt.Video, requester *discordgo.User) *player.Track {
+	track := player.NewTrackFromVideo(video)
+	if requester != nil {
+		track.RequesterID = requester.ID
+	}
+
+	return track
+}
+
+func truncate(input string, length int) string {
+	if len([]rune(input)) <= length {
+		return input
+	}
+
+	return string([]rune(input)[:length-1]) + "…"
+}
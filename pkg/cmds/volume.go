@@ -0,0 +1,104 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ discord.MessageHandler                       = (*Volume)(nil)
+	_ discord.ApplicationCommandInteractionHandler = (*Volume)(nil)
+)
+
+type Volume struct{ Players *player.Manager }
+
+func (cmd Volume) Name() string {
+	return "volume"
+}
+
+func (cmd Volume) Description() string {
+	return "Show or change the volume"
+}
+
+// run shows the volume to anyone, changing it needs the dj role
+func (cmd Volume) run(ctx context.Context, bot *discord.Bot, guildID, channelID string, user *discordgo.User, member *discordgo.Member, level *int) string {
+	if guildID == "" {
+		return guildOnlyReply
+	}
+
+	if level == nil {
+		settings, err := cmd.Players.Settings(ctx, guildID)
+		if err != nil {
+			log.Warn().Err(err).Send()
+			return "could not load the volume"
+		}
+
+		return fmt.Sprintf("🔊 %d%%", settings.Volume)
+	}
+
+	if err := bot.Authorize(guildID, channelID, user, member, discord.PermissionDJ); err != nil {
+		return err.Error()
+	}
+
+	if err := cmd.Players.Get(guildID).SetVolume(ctx, *level); err != nil {
+		log.Warn().Err(err).Send()
+		return err.Error()
+	}
+
+	return fmt.Sprintf("🔊 %d%%", *level)
+}
+
+func (cmd Volume) OnMessage(ctx context.Context, bot *discord.Bot, event *discordgo.MessageCreate, args []string) {
+	var level *int
+	if len(args) > 0 {
+		value, err := strconv.Atoi(args[0])
+		if err != nil {
+			if err := bot.SendMessageReply(ctx, event.Message, "volume must be a number"); err != nil {
+				log.Warn().Err(err).Send()
+			}
+
+			return
+		}
+
+		level = &value
+	}
+
+	if err := bot.SendMessageReply(ctx, event.Message, cmd.run(ctx, bot, event.GuildID, event.ChannelID, event.Author, event.Member, level)); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
+
+func (cmd Volume) ApplicationCommand() *discordgo.ApplicationCommand {
+	minValue := float64(0)
+	return &discordgo.ApplicationCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+		Options: []*discordgo.ApplicationCommandOption{{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "level",
+			Description: fmt.Sprintf("volume in percent, from 0 to %d", player.MaxVolume),
+			MinValue:    &minValue,
+			MaxValue:    player.MaxVolume,
+		}},
+	}
+}
+
+func (cmd Volume) OnApplicationCommand(ctx context.Context, bot *discord.Bot, event *discordgo.InteractionCreate, data *discordgo.ApplicationCommandInteractionData) {
+	var level *int
+	opts := resolveOptions(data.Options)
+	if value, ok := opts["level"].(int64); ok {
+		level = new(int)
+		*level = int(value)
+	}
+
+	reply := cmd.run(ctx, bot, event.GuildID, event.ChannelID, interactionUser(event), event.Member, level)
+	if err := bot.SendInteractionMessageReply(ctx, event.Interaction, reply); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
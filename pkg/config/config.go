@@ -5,6 +5,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/axatol/go-utils/flags"
 	"github.com/axatol/go-utils/ptr"
@@ -26,6 +27,7 @@ var (
 	DiscordAppID         string
 	DiscordBotToken      string
 	DiscordMessagePrefix string
+	DiscordDJRole        string
 
 	MinioEnabled         bool
 	MinioEndpoint        string
@@ -33,15 +35,28 @@ var (
 	MinioAccessKeyID     string
 	MinioSecretAccessKey string
 
+	PlayerAloneTimeout time.Duration
+	PlayerIdleTimeout  time.Duration
+	PlayerVoteSkip     float64
+	PlayerSnapshot     time.Duration
+	PlayerSnapshotAge  time.Duration
+
 	ServerAddress string
 
-	YouTubeAPIKey string
+	SoundboardMaxDuration time.Duration
+	SoundboardDucking     float64
+
+	SponsorBlockURL string
+
+	YouTubeAPIKey        string
+	YouTubePlaylistLimit int
 
 	YTDLPExecutable     string
 	DCAExecutable       string
 	FFMPEGExecutable    string
 	YTDLPCacheDirectory string
 	YTDLPConcurrency    int
+	StreamConcurrency   int
 )
 
 func Version() *zerolog.Logger {
@@ -66,6 +81,7 @@ func Configure() {
 	fs.StringVar(&DiscordAppID, "discord-app-id", "", "discord app id")
 	fs.StringVar(&DiscordBotToken, "discord-bot-token", "", "discord bot token")
 	fs.StringVar(&DiscordMessagePrefix, "discord-message-prefix", "", "discord message prefix")
+	fs.StringVar(&DiscordDJRole, "discord-dj-role", "", "id or name of the role allowed to control playback, empty to allow everyone")
 
 	fs.BoolVar(&MinioEnabled, "minio-enabled", false, "minio enabled")
 	fs.StringVar(&MinioEndpoint, "minio-endpoint", "", "minio endpoint")
@@ -73,15 +89,28 @@ func Configure() {
 	fs.StringVar(&MinioAccessKeyID, "minio-access-key-id", "", "minio access key id")
 	fs.StringVar(&MinioSecretAccessKey, "minio-secret-access-key", "", "minio secret access key")
 
+	fs.DurationVar(&PlayerAloneTimeout, "player-alone-timeout", time.Minute, "how long to stay in a voice channel with no listeners, 0 to disable")
+	fs.DurationVar(&PlayerIdleTimeout, "player-idle-timeout", time.Minute*5, "how long to stay in a voice channel with nothing playing, 0 to disable")
+	fs.DurationVar(&PlayerSnapshot, "player-snapshot-interval", time.Minute, "how often to save queues so they survive restarts, 0 to only save on shutdown")
+	fs.DurationVar(&PlayerSnapshotAge, "player-snapshot-max-age", time.Hour, "how old a saved queue can be and still be restored, 0 to always restore it")
+	fs.Float64Var(&PlayerVoteSkip, "player-vote-skip", 0, "fraction of listeners needed to skip a song someone else requested, 0 to disable voting")
+
 	fs.StringVar(&ServerAddress, "server-address", ":8080", "server address")
 
+	fs.DurationVar(&SoundboardMaxDuration, "soundboard-max-duration", time.Second*10, "longest soundboard clip that can be registered")
+	fs.Float64Var(&SoundboardDucking, "soundboard-ducking", 0.3, "volume of the music while a soundboard clip plays over it, 1 to leave it as is")
+
+	fs.StringVar(&SponsorBlockURL, "sponsorblock-url", "https://sponsor.ajay.app", "sponsorblock compatible api url, empty to disable segment skipping")
+
 	fs.StringVar(&YouTubeAPIKey, "youtube-api-key", "", "youtube api key")
+	fs.IntVar(&YouTubePlaylistLimit, "youtube-playlist-limit", 100, "maximum number of tracks to enqueue from a playlist")
 
 	fs.StringVar(&YTDLPExecutable, "ytdlp-executable", "yt-dlp", "yt-dlp executable")
 	fs.StringVar(&DCAExecutable, "dca-executable", "dca", "dca executable")
 	fs.StringVar(&FFMPEGExecutable, "ffmpeg-executable", "ffmpeg", "ffmpeg executable")
 	fs.StringVar(&YTDLPCacheDirectory, "ytdlp-cache-directory", "/var/cache/ytdlp", "yt-dlp cache directory")
-	fs.IntVar(&YTDLPConcurrency, "ytdlp-concurrency", 3, "yt dlp concurrency")
+	fs.IntVar(&YTDLPConcurrency, "ytdlp-concurrency", 3, "how many yt-dlp lookups run at once, playback is limited by stream-concurrency")
+	fs.IntVar(&StreamConcurrency, "stream-concurrency", 32, "how many yt-dlp, ffmpeg and dca pipelines can play at once, 0 for no limit")
 
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		panic(err)
@@ -121,17 +150,28 @@ func Configure() {
 		Str("discord_app_id", DiscordAppID).
 		Str("discord_bot_token", util.Obscure(DiscordBotToken, 3)).
 		Str("discord_message_prefix", DiscordMessagePrefix).
+		Str("discord_dj_role", DiscordDJRole).
 		Bool("minio_enabled", MinioEnabled).
 		Str("minio_endpoint", MinioEndpoint).
 		Str("minio_bucket", MinioBucket).
 		Str("minio_access_key_id", util.Obscure(MinioAccessKeyID, 3)).
 		Str("minio_secret_access_key", util.Obscure(MinioSecretAccessKey, 3)).
+		Dur("player_alone_timeout", PlayerAloneTimeout).
+		Dur("player_idle_timeout", PlayerIdleTimeout).
+		Float64("player_vote_skip", PlayerVoteSkip).
+		Dur("player_snapshot_interval", PlayerSnapshot).
+		Dur("player_snapshot_max_age", PlayerSnapshotAge).
 		Str("server_address", ServerAddress).
+		Dur("soundboard_max_duration", SoundboardMaxDuration).
+		Float64("soundboard_ducking", SoundboardDucking).
+		Str("sponsorblock_url", SponsorBlockURL).
 		Str("youtube_api_key", util.Obscure(YouTubeAPIKey, 3)).
+		Int("youtube_playlist_limit", YouTubePlaylistLimit).
 		Str("ytdlp_executable", YTDLPExecutable).
 		Str("dca_executable", DCAExecutable).
 		Str("ffmpeg_executable", FFMPEGExecutable).
 		Str("ytdlp_cache_directory", YTDLPCacheDirectory).
 		Int("ytdlp_concurrency", YTDLPConcurrency).
+		Int("stream_concurrency", StreamConcurrency).
 		Send()
 }
@@ -3,6 +3,7 @@ package discord
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/axatol/guosheng/pkg/config"
@@ -24,12 +25,26 @@ type BotOptions struct {
 	AppID         string
 	BotToken      string
 	MessagePrefix string
+	// id or name of the role allowed to control playback, empty for everyone
+	DJRole string
 }
 
+// VoiceListenersHandler is called whenever voice states change in a guild the
+// bot is connected to voice in, with the number of listeners left alongside it
+type VoiceListenersHandler func(guildID, channelID string, listeners int)
+
+// VoiceReconnectHandler is called when the voice connection for a guild is
+// re-established, either because the voice server moved or the gateway resumed
+type VoiceReconnectHandler func(guildID string)
+
 type Bot struct {
 	BotOptions
-	Session  *discordgo.Session
-	Commands map[string]any
+	Session                *discordgo.Session
+	Commands               map[string]any
+	voiceListenersHandlers []VoiceListenersHandler
+	voiceReconnectHandlers []VoiceReconnectHandler
+	ownersMutex            sync.Mutex
+	owners                 []string
 }
 
 func NewBot(opts BotOptions) (*Bot, error) {
@@ -63,6 +78,7 @@ func NewBot(opts BotOptions) (*Bot, error) {
 	bot.Session.AddHandler(bot.onMessageReactionAdd)
 	bot.Session.AddHandler(bot.onRateLimit)
 	bot.Session.AddHandler(bot.onReady)
+	bot.Session.AddHandler(bot.onResumed)
 	bot.Session.AddHandler(bot.onVoiceServerUpdate)
 	bot.Session.AddHandler(bot.onVoiceStateUpdate)
 
@@ -218,6 +234,53 @@ func (b *Bot) GetUserVoiceChannel(userID string) (guildID, channelID string) {
 	return "", ""
 }
 
+func (b *Bot) GetBotVoiceChannel(guildID string) string {
+	b.Session.RLock()
+	defer b.Session.RUnlock()
+
+	if vc, ok := b.Session.VoiceConnections[guildID]; ok {
+		return vc.ChannelID
+	}
+
+	return ""
+}
+
+// GetVoiceChannelListeners returns the ids of users in the voice channel,
+// excluding the bot itself and any other user known to be a bot
+func (b *Bot) GetVoiceChannelListeners(guildID, channelID string) []string {
+	guild, err := b.Session.State.Guild(guildID)
+	if err != nil {
+		return nil
+	}
+
+	var listeners []string
+	for _, state := range guild.VoiceStates {
+		if state.ChannelID != channelID || state.UserID == b.Session.State.User.ID {
+			continue
+		}
+
+		if state.Member != nil && state.Member.User != nil && state.Member.User.Bot {
+			continue
+		}
+
+		if member, err := b.Session.State.Member(guildID, state.UserID); err == nil && member.User != nil && member.User.Bot {
+			continue
+		}
+
+		listeners = append(listeners, state.UserID)
+	}
+
+	return listeners
+}
+
+func (b *Bot) AddVoiceListenersHandler(handler VoiceListenersHandler) {
+	b.voiceListenersHandlers = append(b.voiceListenersHandlers, handler)
+}
+
+func (b *Bot) AddVoiceReconnectHandler(handler VoiceReconnectHandler) {
+	b.voiceReconnectHandlers = append(b.voiceReconnectHandlers, handler)
+}
+
 func (b *Bot) JoinUserVoiceChannel(userID string) (*discordgo.VoiceConnection, error) {
 	guildID, channelID := b.GetUserVoiceChannel(userID)
 
@@ -270,6 +333,14 @@ func (b *Bot) SendMessageReply(ctx context.Context, message *discordgo.Message,
 	return nil
 }
 
+func (b *Bot) SendMessageEmbedReply(ctx context.Context, message *discordgo.Message, embed *discordgo.MessageEmbed) error {
+	if _, err := b.Session.ChannelMessageSendEmbedReply(message.ChannelID, embed, message.Reference(), RequestOptions(ctx)); err != nil {
+		return fmt.Errorf("failed to respond to message %s: %s", message.ID, err)
+	}
+
+	return nil
+}
+
 func (b *Bot) SendInteractionReply(ctx context.Context, interaction *discordgo.Interaction, response *discordgo.InteractionResponse) error {
 	if err := b.Session.InteractionRespond(interaction, response, RequestOptions(ctx)); err != nil {
 		return fmt.Errorf("failed to respond to interaction %s: %s", interaction.ID, err)
@@ -17,13 +17,7 @@ func (b *Bot) onEvent(session *discordgo.Session, event *discordgo.Event) {
 }
 
 func (b *Bot) onInteractionCreate(session *discordgo.Session, event *discordgo.InteractionCreate) {
-	var user *discordgo.User
-	if event.Member != nil && event.Member.User != nil {
-		user = event.Member.User
-	} else if event.User != nil {
-		user = event.User
-	}
-
+	user := interactionUser(event)
 	if user.Bot {
 		return
 	}
@@ -82,6 +76,12 @@ func (b *Bot) onInteractionApplicationCommand(event *discordgo.InteractionCreate
 		return
 	}
 
+	if err := b.Authorize(event.GuildID, event.ChannelID, interactionUser(event), event.Member, commandPermission(cmd)); err != nil {
+		log.Info().Err(err).Send()
+		b.sendForbiddenInteraction(event.Interaction, err)
+		return
+	}
+
 	log.Info().Send()
 	go handler.OnApplicationCommand(context.Background(), b, event, &data)
 }
@@ -125,6 +125,12 @@ func (b *Bot) onInteractionMessageComponent(event *discordgo.InteractionCreate,
 		return
 	}
 
+	if err := b.Authorize(event.GuildID, event.ChannelID, interactionUser(event), event.Member, componentPermission(cmd, interactionID)); err != nil {
+		log.Info().Err(err).Send()
+		b.sendForbiddenInteraction(event.Interaction, err)
+		return
+	}
+
 	log.Info().Send()
 	go handler.OnMessageComponent(context.Background(), b, event, &data)
 }
@@ -173,6 +179,15 @@ func (b *Bot) onMessageCreate(session *discordgo.Session, event *discordgo.Messa
 		return
 	}
 
+	if err := b.Authorize(event.GuildID, event.ChannelID, event.Author, event.Member, commandPermission(cmd)); err != nil {
+		log.Info().Err(err).Send()
+		if err := b.SendMessageReply(context.Background(), event.Message, err.Error()); err != nil {
+			log.Warn().Err(err).Send()
+		}
+
+		return
+	}
+
 	go command.OnMessage(context.Background(), b, event, args)
 }
 
@@ -215,15 +230,46 @@ func (b *Bot) onReady(session *discordgo.Session, event *discordgo.Ready) {
 	}
 }
 
+func (b *Bot) onResumed(session *discordgo.Session, event *discordgo.Resumed) {
+	log.Info().
+		Str("event", "RESUMED").
+		Send()
+
+	session.RLock()
+	guildIDs := make([]string, 0, len(session.VoiceConnections))
+	for guildID := range session.VoiceConnections {
+		guildIDs = append(guildIDs, guildID)
+	}
+
+	session.RUnlock()
+
+	for _, guildID := range guildIDs {
+		for _, handler := range b.voiceReconnectHandlers {
+			go handler(guildID)
+		}
+	}
+}
+
 func (b *Bot) onVoiceServerUpdate(session *discordgo.Session, event *discordgo.VoiceServerUpdate) {
 	log.Info().
 		Str("event", "VOICE_SERVER_UPDATE").
 		Str("endpoint", event.Endpoint).
 		Str("guild_id", event.GuildID).
 		Send()
+
+	for _, handler := range b.voiceReconnectHandlers {
+		go handler(event.GuildID)
+	}
 }
 
 func (b *Bot) onVoiceStateUpdate(session *discordgo.Session, event *discordgo.VoiceStateUpdate) {
+	if channelID := b.GetBotVoiceChannel(event.GuildID); channelID != "" {
+		listeners := len(b.GetVoiceChannelListeners(event.GuildID, channelID))
+		for _, handler := range b.voiceListenersHandlers {
+			go handler(event.GuildID, channelID, listeners)
+		}
+	}
+
 	oldChannelId := ""
 	if event.BeforeUpdate != nil {
 		oldChannelId = event.BeforeUpdate.ChannelID
@@ -38,6 +38,16 @@ func (e *MessageEmbed) SetURL(url string) *MessageEmbed {
 	return e
 }
 
+func (e *MessageEmbed) SetDescription(description string) *MessageEmbed {
+	e.Description = description
+	return e
+}
+
+func (e *MessageEmbed) SetFooter(text string) *MessageEmbed {
+	e.Footer = &discordgo.MessageEmbedFooter{Text: text}
+	return e
+}
+
 func (e *MessageEmbed) AddField(name, value string, isInline ...bool) *MessageEmbed {
 	inline := true
 	if len(isInline) > 0 {
@@ -0,0 +1,215 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+type Permission int
+
+const (
+	// anyone can use the command
+	PermissionEveryone Permission = iota
+	// members with the dj role or who can manage the guild
+	PermissionDJ
+	// members who can manage the guild, whether or not there is a dj role
+	PermissionAdmin
+	// only the owner of the bot application, or members of its team
+	PermissionOwner
+)
+
+var (
+	ErrForbidden = errors.New("not allowed to use this command")
+)
+
+// PermissionedCommand is implemented by commands that need more than
+// PermissionEveryone to be dispatched
+type PermissionedCommand interface {
+	Permission() Permission
+}
+
+// ComponentPermissionedCommand is implemented by commands whose message
+// components need a different permission to the command itself, it is given
+// the part of the custom id after the command name
+type ComponentPermissionedCommand interface {
+	ComponentPermission(interactionID string) Permission
+}
+
+func (p Permission) String() string {
+	switch p {
+	case PermissionDJ:
+		return "dj"
+	case PermissionAdmin:
+		return "admin"
+	case PermissionOwner:
+		return "owner"
+	default:
+		return "everyone"
+	}
+}
+
+func commandPermission(cmd any) Permission {
+	if command, ok := cmd.(PermissionedCommand); ok {
+		return command.Permission()
+	}
+
+	return PermissionEveryone
+}
+
+func componentPermission(cmd any, interactionID string) Permission {
+	if command, ok := cmd.(ComponentPermissionedCommand); ok {
+		return command.ComponentPermission(interactionID)
+	}
+
+	return commandPermission(cmd)
+}
+
+// Authorize checks the member is allowed to use commands that require the
+// permission, the member may be nil outside of guilds
+func (b *Bot) Authorize(guildID, channelID string, user *discordgo.User, member *discordgo.Member, permission Permission) error {
+	if permission == PermissionEveryone {
+		return nil
+	}
+
+	owner, err := b.isOwner(user.ID)
+	if err != nil {
+		log.Warn().Err(err).Send()
+	}
+
+	if owner {
+		return nil
+	}
+
+	if permission == PermissionOwner {
+		return fmt.Errorf("%s: only the bot owner can use this command", ErrForbidden)
+	}
+
+	if permission == PermissionAdmin {
+		if member == nil {
+			return fmt.Errorf("%s: commands must be used in a server", ErrForbidden)
+		}
+
+		if b.canManage(channelID, user, member) {
+			return nil
+		}
+
+		return fmt.Errorf("%s: the manage server permission is required", ErrForbidden)
+	}
+
+	// without a dj role playback controls are open to everyone
+	if b.DJRole == "" {
+		return nil
+	}
+
+	if member == nil {
+		return fmt.Errorf("%s: commands must be used in a server", ErrForbidden)
+	}
+
+	if b.isDJ(guildID, channelID, user, member) {
+		return nil
+	}
+
+	return fmt.Errorf("%s: the %s role is required", ErrForbidden, b.DJRole)
+}
+
+// IsDJ reports whether the member has the dj role or can manage the guild,
+// unlike Authorize it is false for everyone else even without a dj role
+func (b *Bot) IsDJ(guildID, channelID string, user *discordgo.User, member *discordgo.Member) bool {
+	owner, err := b.isOwner(user.ID)
+	if err != nil {
+		log.Warn().Err(err).Send()
+	}
+
+	return owner || (member != nil && b.isDJ(guildID, channelID, user, member))
+}
+
+func (b *Bot) isDJ(guildID, channelID string, user *discordgo.User, member *discordgo.Member) bool {
+	if b.canManage(channelID, user, member) {
+		return true
+	}
+
+	if b.DJRole == "" {
+		return false
+	}
+
+	for _, roleID := range member.Roles {
+		if roleID == b.DJRole {
+			return true
+		}
+
+		if role, err := b.Session.State.Role(guildID, roleID); err == nil && strings.EqualFold(role.Name, b.DJRole) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *Bot) canManage(channelID string, user *discordgo.User, member *discordgo.Member) bool {
+	permissions := member.Permissions
+	if permissions == 0 {
+		// members attached to messages do not carry their permissions
+		permissions, _ = b.Session.State.UserChannelPermissions(user.ID, channelID)
+	}
+
+	return permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageServer) != 0
+}
+
+// isOwner looks up the owners of the application once and remembers them
+func (b *Bot) isOwner(userID string) (bool, error) {
+	b.ownersMutex.Lock()
+	defer b.ownersMutex.Unlock()
+
+	if b.owners == nil {
+		application, err := b.Session.Application(b.AppID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get application %s: %s", b.AppID, err)
+		}
+
+		owners := []string{}
+		if application.Owner != nil {
+			owners = append(owners, application.Owner.ID)
+		}
+
+		if application.Team != nil {
+			for _, member := range application.Team.Members {
+				if member.User != nil {
+					owners = append(owners, member.User.ID)
+				}
+			}
+		}
+
+		b.owners = owners
+	}
+
+	return slices.Contains(b.owners, userID), nil
+}
+
+func interactionUser(event *discordgo.InteractionCreate) *discordgo.User {
+	if event.Member != nil && event.Member.User != nil {
+		return event.Member.User
+	}
+
+	return event.User
+}
+
+// sendForbiddenInteraction tells only the user why the command was refused
+func (b *Bot) sendForbiddenInteraction(interaction *discordgo.Interaction, err error) {
+	response := discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: err.Error(),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}
+
+	if err := b.SendInteractionReply(context.Background(), interaction, &response); err != nil {
+		log.Warn().Err(err).Send()
+	}
+}
@@ -0,0 +1,113 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/axatol/guosheng/pkg/yt"
+)
+
+const (
+	// how many played tracks autoplay avoids repeating
+	recentLimit = 50
+	// how many search results to consider for each recommendation
+	recommendCandidates = 10
+)
+
+// Recommender picks a track to follow on from the seed, avoiding the excluded
+// track ids
+type Recommender interface {
+	Recommend(ctx context.Context, seed *Track, exclude []string) (*Track, error)
+}
+
+var (
+	_ Recommender = (*YouTubeRecommender)(nil)
+)
+
+// YouTubeRecommender searches youtube for videos like the seed track
+type YouTubeRecommender struct {
+	YouTube *yt.Client
+}
+
+func (r *YouTubeRecommender) Recommend(ctx context.Context, seed *Track, exclude []string) (*Track, error) {
+	query := fmt.Sprintf("%s %s", seed.Title, seed.Uploader)
+	results, err := r.YouTube.SearchVideo(ctx, query, recommendCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if result.ID == seed.ID || slices.Contains(exclude, result.ID) {
+			continue
+		}
+
+		// search results do not include the duration
+		video, err := r.YouTube.GetVideoByID(ctx, result.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		track := NewTrackFromVideo(video)
+		track.Autoplay = true
+		return track, nil
+	}
+
+	return nil, fmt.Errorf("no recommendations found for track %s", seed.ID)
+}
+
+func (p *Player) Autoplay() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.autoplay
+}
+
+func (p *Player) SetAutoplay(enabled bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.autoplay = enabled
+}
+
+// remember records the track as recently played so autoplay does not pick it
+func (p *Player) remember(track *Track) {
+	if track == nil {
+		return
+	}
+
+	p.recent = append(p.recent, track.ID)
+	if len(p.recent) > recentLimit {
+		p.recent = p.recent[len(p.recent)-recentLimit:]
+	}
+}
+
+func (p *Player) shouldAutoplay() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.autoplay && !p.stopped && p.manager.Recommender != nil
+}
+
+// recommend finds a track to follow on from the seed and starts it, or
+// returns nil if nothing suitable was found
+func (p *Player) recommend(ctx context.Context, seed *Track) *Track {
+	p.mutex.RLock()
+	exclude := slices.Clone(p.recent)
+	p.mutex.RUnlock()
+
+	track, err := p.manager.Recommender.Recommend(ctx, seed, exclude)
+	if err != nil {
+		p.log.Warn().Err(err).Str("track_id", seed.ID).Msg("failed to autoplay")
+		return nil
+	}
+
+	p.mutex.Lock()
+	// the player may have been stopped while searching
+	if p.channelID == "" {
+		p.mutex.Unlock()
+		return nil
+	}
+
+	p.queue = append(p.queue, track)
+	p.mutex.Unlock()
+
+	return p.next(nil, false)
+}
@@ -0,0 +1,118 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// how far into a chapter going back restarts it rather than going to the
+// previous chapter
+const chapterRestartThreshold = time.Second * 3
+
+type Chapter struct {
+	Title string        `json:"title"`
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+}
+
+// ChapterSource is implemented by sources that can look up the chapters of a
+// track after it has been resolved
+type ChapterSource interface {
+	Source
+	Chapters(ctx context.Context, track *Track) ([]Chapter, error)
+}
+
+// loadChapters looks up the chapters of the track in the background so
+// playback does not wait on it
+func (p *Player) loadChapters(ctx context.Context, track *Track) {
+	chapters := track.Chapters
+	if len(chapters) < 1 && !track.Live {
+		origin, err := p.manager.source(track.Source)
+		if err != nil {
+			return
+		}
+
+		lookup, ok := origin.(ChapterSource)
+		if !ok {
+			return
+		}
+
+		if chapters, err = lookup.Chapters(ctx, track); err != nil {
+			p.log.Warn().Err(err).Str("track_id", track.ID).Msg("failed to load chapters")
+			return
+		}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// playback may have moved on while looking them up
+	if p.current == track && ctx.Err() == nil {
+		p.chapters = chapters
+	}
+}
+
+// CurrentChapter returns the chapters of the current track and the index of
+// the one being played, or -1 if there are none
+func (p *Player) CurrentChapter() (int, []Chapter) {
+	p.mutex.RLock()
+	chapters := p.chapters
+	p.mutex.RUnlock()
+
+	return chapterAt(chapters, p.Position()), chapters
+}
+
+// SeekChapter jumps to the next or previous chapter, or the first chapter
+// whose title contains the input
+func (p *Player) SeekChapter(input string) (*Chapter, error) {
+	index, chapters := p.CurrentChapter()
+	if len(chapters) < 1 {
+		return nil, fmt.Errorf("the current track has no chapters")
+	}
+
+	target := -1
+	switch strings.ToLower(input) {
+	case "next":
+		target = index + 1
+	case "prev", "previous":
+		target = index
+		if index < 0 || p.Position()-chapters[index].Start < chapterRestartThreshold {
+			target = index - 1
+		}
+
+		target = max(target, 0)
+	default:
+		for i, chapter := range chapters {
+			if strings.Contains(strings.ToLower(chapter.Title), strings.ToLower(input)) {
+				target = i
+				break
+			}
+		}
+	}
+
+	if target < 0 || target >= len(chapters) {
+		return nil, fmt.Errorf("no chapter found for %s", input)
+	}
+
+	chapter := chapters[target]
+	if !p.Seek(chapter.Start) {
+		return nil, fmt.Errorf("nothing is playing")
+	}
+
+	return &chapter, nil
+}
+
+func chapterAt(chapters []Chapter, position time.Duration) int {
+	index := -1
+	for i, chapter := range chapters {
+		if chapter.Start > position {
+			break
+		}
+
+		index = i
+	}
+
+	return index
+}
@@ -0,0 +1,292 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/axatol/guosheng/pkg/audio"
+	"github.com/bwmarrin/discordgo"
+)
+
+// PlayClip plays a short clip as soon as possible, it is mixed over the
+// current track if there is one, a clip that has not started yet is replaced
+func (p *Player) PlayClip(channelID string, clip audio.FrameReader) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.current == nil || p.channelID == "" {
+		p.channelID = channelID
+	}
+
+	p.clip = clip
+	p.signal()
+
+	select {
+	case p.clipNotify <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Player) takeClip() audio.FrameReader {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// the clip is handled now, a notification left behind would interrupt
+	// the next pause for nothing
+	select {
+	case <-p.clipNotify:
+	default:
+	}
+
+	clip := p.clip
+	p.clip = nil
+	return clip
+}
+
+// sendClip sends every frame of the clip, the connection may be replaced if
+// it had to be rejoined
+func (p *Player) sendClip(ctx context.Context, vc *discordgo.VoiceConnection, clip audio.FrameReader) (*discordgo.VoiceConnection, error) {
+	for {
+		frame, err := clip.ReadFrame()
+		if err != nil {
+			if err == io.EOF {
+				return vc, nil
+			}
+
+			return vc, fmt.Errorf("failed to read clip: %s", err)
+		}
+
+		if vc, err = p.send(ctx, vc, frame); err != nil {
+			return vc, err
+		}
+	}
+}
+
+// playIdleClip plays a pending clip while nothing else is playing
+func (p *Player) playIdleClip(ctx context.Context) {
+	clip := p.takeClip()
+	if clip == nil {
+		return
+	}
+
+	vc, err := p.join()
+	if err != nil {
+		p.log.Warn().Err(err).Send()
+		return
+	}
+
+	if err := vc.Speaking(true); err != nil {
+		p.log.Warn().Err(fmt.Errorf("failed to start speaking: %s", err)).Send()
+	}
+
+	if vc, err = p.sendClip(ctx, vc, clip); err != nil && ctx.Err() == nil {
+		p.log.Warn().Err(err).Send()
+	}
+
+	if err := vc.Speaking(false); err != nil {
+		p.log.Warn().Err(fmt.Errorf("failed to stop speaking: %s", err)).Send()
+	}
+}
+
+const (
+	// frames of the track read ahead of the clip, they are sent while the mix
+	// is prepared so the clip follows on without a gap
+	clipLead = 25
+	// frames decoded ahead of the mixed part of the track to prime the
+	// decoder, as recommended when seeking opus
+	clipPreroll = 4
+)
+
+// overlayFrame is a frame to send and the position in the track after it
+type overlayFrame struct {
+	frame    []byte
+	position int64
+}
+
+// overlayClip plays the clip over the track from the current position, the
+// track is ducked for the length of the clip and carries on from where the
+// clip ends, io.EOF is returned if the track ended during the clip
+//
+// live tracks cannot be read ahead and a paused track has nothing to mix
+// with, so the clip interrupts them instead
+func (p *Player) overlayClip(ctx context.Context, vc *discordgo.VoiceConnection, track *Track, stream *trackStream, position int64, clip audio.FrameReader) (*discordgo.VoiceConnection, int64, error) {
+	if track.Live || p.Paused() {
+		vc, err := p.sendClip(ctx, vc, clip)
+		return vc, position, err
+	}
+
+	clipFrames, err := readFrames(clip, -1)
+	if err != nil {
+		return vc, position, fmt.Errorf("failed to read clip: %s", err)
+	}
+
+	// the stream belongs to prepareClip until it is done, there is room for
+	// everything it sends so it never waits on a failed send
+	frames := make(chan overlayFrame, clipLead+len(clipFrames)*2)
+	done := make(chan error, 1)
+	go func() {
+		done <- p.prepareClip(ctx, track, stream, position, clipFrames, frames)
+	}()
+
+	for frame := range frames {
+		if vc, err = p.send(ctx, vc, frame.frame); err != nil {
+			<-done
+			return vc, position, err
+		}
+
+		position = frame.position
+		p.elapsed.Store(position)
+	}
+
+	return vc, position, <-done
+}
+
+// prepareClip passes on the lead frames of the track as soon as they are read
+// then mixes the clip over the frames that follow while the lead is playing
+func (p *Player) prepareClip(ctx context.Context, track *Track, stream *trackStream, position int64, clipFrames [][]byte, frames chan<- overlayFrame) error {
+	defer close(frames)
+
+	var lead [][]byte
+	for len(lead) < clipLead {
+		frame, err := stream.ReadFrame()
+		if err == io.EOF {
+			// the track ended before there was anything to mix with
+			for _, frame := range clipFrames {
+				frames <- overlayFrame{frame, position}
+			}
+
+			return io.EOF
+		}
+
+		if err != nil {
+			return err
+		}
+
+		lead = append(lead, frame)
+		position = stream.position()
+		frames <- overlayFrame{frame, position}
+	}
+
+	musicFrames, err := readFrames(stream, len(clipFrames))
+	if err != nil {
+		return err
+	}
+
+	mixed, err := p.mix(ctx, track, lead[len(lead)-clipPreroll:], musicFrames, clipFrames)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// nothing of the clip has been sent yet so it can still interrupt
+		p.log.Warn().Err(err).Str("track_id", track.ID).Msg("could not mix clip, interrupting instead")
+		mixed = append(clipFrames, musicFrames...)
+	}
+
+	// progress moves with the track rather than the frames being sent
+	start, end := position, stream.position()
+	for i, frame := range mixed {
+		frames <- overlayFrame{frame, start + (end-start)*int64(i+1)/int64(len(mixed))}
+	}
+
+	if len(musicFrames) < len(clipFrames) {
+		return io.EOF
+	}
+
+	return nil
+}
+
+// mix decodes the track and clip frames, lays the clip over the ducked track
+// and encodes the result back into frames, the preroll frames of the track
+// only prime the decoder
+func (p *Player) mix(ctx context.Context, track *Track, preroll, musicFrames, clipFrames [][]byte) ([][]byte, error) {
+	decodedClip := make(chan error, 1)
+	var clip []byte
+	go func() {
+		var err error
+		clip, err = p.decodeFrames(ctx, "clip", nil, clipFrames)
+		decodedClip <- err
+	}()
+
+	music, err := p.decodeFrames(ctx, track.ID, preroll, musicFrames)
+	if clipErr := <-decodedClip; err == nil {
+		err = clipErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	mixed := audio.Mix(music, clip, p.manager.ClipDucking)
+	encoded, err := p.manager.CLI.EncodePCM(ctx, track.ID, bytes.NewReader(mixed))
+	if err != nil {
+		return nil, err
+	}
+
+	frames, err := readFrames(audio.NewDCAReader(encoded), -1)
+	if closeErr := encoded.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode mixed clip: %s", err)
+	}
+
+	return frames, nil
+}
+
+// decodeFrames turns opus frames back into pcm by way of an ogg container,
+// the pcm of the preroll frames is skipped
+func (p *Player) decodeFrames(ctx context.Context, id string, preroll, frames [][]byte) ([]byte, error) {
+	container := bytes.Buffer{}
+	writer, err := audio.NewOggWriter(&container, len(preroll)*audio.FrameSamples)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, frames := range [][][]byte{preroll, frames} {
+		for _, frame := range frames {
+			if err := writer.WriteFrame(frame); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	decoded, err := p.manager.CLI.Decode(ctx, id, &container)
+	if err != nil {
+		return nil, err
+	}
+
+	pcm, err := io.ReadAll(decoded)
+	if closeErr := decoded.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %s", id, err)
+	}
+
+	return pcm, nil
+}
+
+// readFrames reads up to limit frames, or all of them if limit is negative,
+// stopping early without error if the reader runs out
+func readFrames(reader audio.FrameReader, limit int) ([][]byte, error) {
+	var frames [][]byte
+	for limit < 0 || len(frames) < limit {
+		frame, err := reader.ReadFrame()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return frames, err
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
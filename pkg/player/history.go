@@ -0,0 +1,152 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/cache"
+)
+
+// how many plays are kept per guild, the oldest are dropped first
+const historyLimit = 200
+
+type HistoryOutcome string
+
+const (
+	HistoryPlaying   HistoryOutcome = "playing"
+	HistoryCompleted HistoryOutcome = "completed"
+	HistorySkipped   HistoryOutcome = "skipped"
+	HistoryStopped   HistoryOutcome = "stopped"
+	HistoryFailed    HistoryOutcome = "failed"
+)
+
+// HistoryEntry is a track that was started in a guild and how it ended
+type HistoryEntry struct {
+	Track     *Track         `json:"track"`
+	StartedAt time.Time      `json:"started_at"`
+	Outcome   HistoryOutcome `json:"outcome"`
+}
+
+func historyKey(guildID string) string {
+	return fmt.Sprintf("guilds/%s/history.json", guildID)
+}
+
+// History returns what has been played in the guild, most recent first
+func (m *Manager) History(ctx context.Context, guildID string) ([]HistoryEntry, error) {
+	m.historyMutex.Lock()
+	defer m.historyMutex.Unlock()
+
+	return m.loadHistory(ctx, guildID)
+}
+
+func (m *Manager) loadHistory(ctx context.Context, guildID string) ([]HistoryEntry, error) {
+	raw, err := m.ObjectStore.Get(ctx, historyKey(guildID))
+	if err != nil {
+		if err == cache.ErrObjectNotFound {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to load history for guild %s: %s", guildID, err)
+	}
+
+	var history []HistoryEntry
+	if err := json.Unmarshal(raw, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse history for guild %s: %s", guildID, err)
+	}
+
+	return history, nil
+}
+
+// recordHistory adds the entry or updates the outcome of the entry for the
+// same play, an entry that has already ended is never marked as playing again
+func (m *Manager) recordHistory(ctx context.Context, guildID string, entry HistoryEntry) error {
+	m.historyMutex.Lock()
+	defer m.historyMutex.Unlock()
+
+	history, err := m.loadHistory(ctx, guildID)
+	if err != nil {
+		return err
+	}
+
+	index := slices.IndexFunc(history, func(existing HistoryEntry) bool {
+		return existing.StartedAt.Equal(entry.StartedAt) && existing.Track.ID == entry.Track.ID
+	})
+
+	switch {
+	case index < 0:
+		history = append([]HistoryEntry{entry}, history...)
+		history = history[:min(len(history), historyLimit)]
+	case entry.Outcome != HistoryPlaying:
+		history[index].Outcome = entry.Outcome
+	default:
+		return nil
+	}
+
+	raw, err := json.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history for guild %s: %s", guildID, err)
+	}
+
+	if _, err := m.ObjectStore.Put(ctx, historyKey(guildID), raw, nil); err != nil {
+		return fmt.Errorf("failed to save history for guild %s: %s", guildID, err)
+	}
+
+	return nil
+}
+
+// recordStart adds the track to the history of the guild once its first
+// frame has been sent, tracks that fail before then are not worth keeping
+func (p *Player) recordStart(ctx context.Context, track *Track) {
+	startedAt := time.Now()
+
+	p.mutex.Lock()
+	p.startedAt = startedAt
+	p.mutex.Unlock()
+
+	// saving is left out of the way of playback, the outcome is only filled
+	// in once the track ends
+	entry := HistoryEntry{Track: historyTrack(track), StartedAt: startedAt, Outcome: HistoryPlaying}
+	go func() {
+		if err := p.manager.recordHistory(context.WithoutCancel(ctx), p.guildID, entry); err != nil {
+			p.log.Warn().Err(err).Send()
+		}
+	}()
+}
+
+// record updates the history entry of the track with how it ended
+func (p *Player) record(ctx context.Context, track *Track, err error) {
+	p.mutex.RLock()
+	skipped, stopped, startedAt := p.skipped, p.stopped, p.startedAt
+	p.mutex.RUnlock()
+
+	if startedAt.IsZero() {
+		return
+	}
+
+	outcome := HistoryCompleted
+	switch {
+	case err != nil:
+		outcome = HistoryFailed
+	case stopped || ctx.Err() != nil:
+		outcome = HistoryStopped
+	case skipped:
+		outcome = HistorySkipped
+	}
+
+	entry := HistoryEntry{Track: historyTrack(track), StartedAt: startedAt, Outcome: outcome}
+	if err := p.manager.recordHistory(context.WithoutCancel(ctx), p.guildID, entry); err != nil {
+		p.log.Warn().Err(err).Send()
+	}
+}
+
+// historyTrack copies the track without the details of the playback itself,
+// which are not worth keeping
+func historyTrack(track *Track) *Track {
+	recorded := *track
+	recorded.Start = 0
+	recorded.Chapters = nil
+	return &recorded
+}
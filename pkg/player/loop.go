@@ -0,0 +1,60 @@
+package player
+
+import (
+	"fmt"
+	"strings"
+)
+
+type LoopMode string
+
+const (
+	LoopOff   LoopMode = "off"
+	LoopTrack LoopMode = "track"
+	LoopQueue LoopMode = "queue"
+)
+
+var LoopModes = []LoopMode{LoopOff, LoopTrack, LoopQueue}
+
+func (m LoopMode) String() string {
+	return string(m)
+}
+
+func ParseLoopMode(input string) (LoopMode, error) {
+	for _, mode := range LoopModes {
+		if strings.EqualFold(input, mode.String()) {
+			return mode, nil
+		}
+	}
+
+	return LoopOff, fmt.Errorf("invalid loop mode %s", input)
+}
+
+func (p *Player) Loop() LoopMode {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.loop
+}
+
+func (p *Player) SetLoop(mode LoopMode) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.loop = mode
+}
+
+// requeue puts the track that just ended back into the queue according to the
+// loop mode, tracks that were stopped or failed to play are never requeued
+func (p *Player) requeue(previous *Track, failed bool) {
+	if previous == nil || failed || p.stopped {
+		return
+	}
+
+	replay := *previous
+	replay.Start = 0
+
+	switch {
+	case p.loop == LoopTrack && !p.skipped:
+		p.queue = append([]*Track{&replay}, p.queue...)
+	case p.loop == LoopQueue:
+		p.queue = append(p.queue, &replay)
+	}
+}
@@ -0,0 +1,94 @@
+package player
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/cache"
+	"github.com/axatol/guosheng/pkg/cli"
+	"github.com/bwmarrin/discordgo"
+)
+
+type ManagerOptions struct {
+	Session     *discordgo.Session
+	CLI         *cli.Executor
+	ObjectStore cache.ObjectStore
+	// how long to stay in a voice channel with no listeners
+	AloneTimeout time.Duration
+	// how long to stay in a voice channel with nothing playing
+	IdleTimeout time.Duration
+	// picks what to play next when autoplay is on and the queue runs dry
+	Recommender Recommender
+	// checked in order, the first to match an input resolves it
+	Sources []Source
+	// looks up parts of tracks to skip, nil to disable
+	Segments SegmentProvider
+	// fraction of listeners that must vote to skip a track someone else
+	// requested, 0 lets anyone skip
+	VoteSkipRatio float64
+	// how often to save the state of players, 0 to only save on close
+	SnapshotInterval time.Duration
+	// snapshots older than this are not restored, 0 to always restore them
+	SnapshotMaxAge time.Duration
+	// volume of the track while a clip is mixed over it, 1 leaves it as is
+	ClipDucking float64
+}
+
+type Manager struct {
+	ManagerOptions
+	ctx           context.Context
+	cancel        context.CancelFunc
+	mutex         sync.Mutex
+	players       map[string]*Player
+	snapshotMutex sync.Mutex
+	historyMutex  sync.Mutex
+	closed        bool
+}
+
+// NewManager starts a manager whose players run until the manager is closed
+// or the context is done
+func NewManager(ctx context.Context, opts ManagerOptions) *Manager {
+	ctx, cancel := context.WithCancel(ctx)
+	manager := Manager{
+		ManagerOptions: opts,
+		ctx:            ctx,
+		cancel:         cancel,
+		players:        make(map[string]*Player),
+	}
+
+	go manager.watchSnapshots()
+	return &manager
+}
+
+// Get returns the player for the guild, starting one if it does not exist yet
+func (m *Manager) Get(guildID string) *Player {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if player, ok := m.players[guildID]; ok {
+		return player
+	}
+
+	player := newPlayer(m, guildID)
+	settings, err := m.loadSettings(m.ctx, guildID)
+	if err != nil {
+		player.log.Warn().Err(err).Send()
+	}
+
+	player.settings = settings
+	m.players[guildID] = player
+	go player.run(m.ctx)
+	go player.watchNowPlaying(m.ctx)
+
+	return player
+}
+
+// Lookup returns the player for the guild only if one has been started
+func (m *Manager) Lookup(guildID string) (*Player, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	player, ok := m.players[guildID]
+	return player, ok
+}
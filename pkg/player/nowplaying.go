@@ -0,0 +1,235 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/util"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// buttons on the now playing message are dispatched to the command of
+	// this name using the "command:action" custom id format
+	NowPlayingCommandName = "nowplaying"
+	nowPlayingInterval    = time.Second * 15
+	nowPlayingBarWidth    = 16
+)
+
+type NowPlayingAction string
+
+const (
+	NowPlayingPause   NowPlayingAction = "pause"
+	NowPlayingResume  NowPlayingAction = "resume"
+	NowPlayingSkip    NowPlayingAction = "skip"
+	NowPlayingStop    NowPlayingAction = "stop"
+	NowPlayingLoop    NowPlayingAction = "loop"
+	NowPlayingShuffle NowPlayingAction = "shuffle"
+)
+
+func (a NowPlayingAction) CustomID() string {
+	return fmt.Sprintf("%s:%s", NowPlayingCommandName, a)
+}
+
+// Shuffle randomises the order of the queue
+func (p *Player) Shuffle() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	rand.Shuffle(len(p.queue), func(i, j int) {
+		p.queue[i], p.queue[j] = p.queue[j], p.queue[i]
+	})
+}
+
+// SetTextChannel sets where the now playing message is posted, a message in
+// a different channel is left behind and a new one will be posted
+func (p *Player) SetTextChannel(channelID string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.textChannelID != channelID {
+		p.textChannelID = channelID
+		p.nowPlayingMessageID = ""
+	}
+}
+
+func (p *Player) NowPlayingEmbed() *discordgo.MessageEmbed {
+	track := p.Current()
+	if track == nil {
+		return discord.NewMessageEmbed().
+			SetTitle("Nothing playing").
+			Embed()
+	}
+
+	elapsed := p.Position()
+	progress := fmt.Sprintf("`%s / %s`", util.FormatDuration(elapsed), track.DurationString())
+	if track.Duration > 0 {
+		progress = fmt.Sprintf("%s\n%s", progress, util.ProgressBar(elapsed.Seconds(), track.Duration.Seconds(), nowPlayingBarWidth))
+	}
+
+	status := "▶️ Playing"
+	if p.Paused() {
+		status = "⏸️ Paused"
+	}
+
+	nextUp := "—"
+	queue := p.Queue()
+	if len(queue) < 1 && p.Autoplay() {
+		nextUp = "autoplay"
+	} else if len(queue) > 0 {
+		nextUp = util.MDLink(queue[0].Title, queue[0].URL)
+		if len(queue) > 1 {
+			nextUp = fmt.Sprintf("%s (+%d more)", nextUp, len(queue)-1)
+		}
+	}
+
+	requester := "?"
+	if track.RequesterID != "" {
+		requester = fmt.Sprintf("<@%s>", track.RequesterID)
+	} else if track.Autoplay {
+		requester = "autoplay"
+	}
+
+	settings := p.Settings()
+	embed := discord.NewMessageEmbed().
+		SetTitle(track.Title).
+		SetURL(track.URL).
+		SetDescription(status).
+		AddField("Uploader", util.MDLink(track.Uploader, track.UploaderURL)).
+		AddField("Requested by", requester).
+		AddField("Progress", progress, false)
+
+	if votes, needed := p.SkipVotes(); votes > 0 {
+		embed.AddField("Skip votes", fmt.Sprintf("%d/%d", votes, needed))
+	}
+
+	if index, chapters := p.CurrentChapter(); index >= 0 {
+		chapter := chapters[index]
+		embed.AddField("Chapter", fmt.Sprintf("%s (%d/%d)", chapter.Title, index+1, len(chapters)), false)
+	}
+
+	return embed.
+		AddField("Loop", p.Loop().String()).
+		AddField("Volume", fmt.Sprintf("%d%%", settings.Volume)).
+		AddField("Next up", nextUp, false).
+		Embed()
+}
+
+func (p *Player) NowPlayingComponents() []discordgo.MessageComponent {
+	if p.Current() == nil {
+		return []discordgo.MessageComponent{}
+	}
+
+	toggle := discordgo.Button{Label: "Pause", Emoji: discordgo.ComponentEmoji{Name: "⏸️"}, Style: discordgo.SecondaryButton, CustomID: NowPlayingPause.CustomID()}
+	if p.Paused() {
+		toggle = discordgo.Button{Label: "Resume", Emoji: discordgo.ComponentEmoji{Name: "▶️"}, Style: discordgo.PrimaryButton, CustomID: NowPlayingResume.CustomID()}
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				toggle,
+				discordgo.Button{Label: "Skip", Emoji: discordgo.ComponentEmoji{Name: "⏭️"}, Style: discordgo.SecondaryButton, CustomID: NowPlayingSkip.CustomID()},
+				discordgo.Button{Label: "Stop", Emoji: discordgo.ComponentEmoji{Name: "⏹️"}, Style: discordgo.DangerButton, CustomID: NowPlayingStop.CustomID()},
+				discordgo.Button{Label: "Loop", Emoji: discordgo.ComponentEmoji{Name: "🔁"}, Style: discordgo.SecondaryButton, CustomID: NowPlayingLoop.CustomID()},
+				discordgo.Button{Label: "Shuffle", Emoji: discordgo.ComponentEmoji{Name: "🔀"}, Style: discordgo.SecondaryButton, CustomID: NowPlayingShuffle.CustomID()},
+			},
+		},
+	}
+}
+
+// PostNowPlaying sends a new now playing message to the text channel,
+// replacing the previous one
+func (p *Player) PostNowPlaying(ctx context.Context, channelID string) error {
+	p.mutex.Lock()
+	previousChannelID, previousMessageID := p.textChannelID, p.nowPlayingMessageID
+	p.textChannelID = channelID
+	p.nowPlayingMessageID = ""
+	p.mutex.Unlock()
+
+	if previousMessageID != "" {
+		if err := p.manager.Session.ChannelMessageDelete(previousChannelID, previousMessageID, discord.RequestOptions(ctx)); err != nil {
+			p.log.Warn().Err(fmt.Errorf("failed to delete now playing message %s: %s", previousMessageID, err)).Send()
+		}
+	}
+
+	return p.refreshNowPlaying(ctx)
+}
+
+// refreshNowPlaying edits the now playing message, posting one if it does not
+// exist yet
+func (p *Player) refreshNowPlaying(ctx context.Context) error {
+	p.mutex.RLock()
+	channelID, messageID := p.textChannelID, p.nowPlayingMessageID
+	p.mutex.RUnlock()
+
+	if channelID == "" {
+		return nil
+	}
+
+	embeds := []*discordgo.MessageEmbed{p.NowPlayingEmbed()}
+	components := p.NowPlayingComponents()
+
+	if messageID != "" {
+		edit := discordgo.MessageEdit{ID: messageID, Channel: channelID, Embeds: embeds, Components: components}
+		if _, err := p.manager.Session.ChannelMessageEditComplex(&edit, discord.RequestOptions(ctx)); err != nil {
+			return fmt.Errorf("failed to edit now playing message %s: %s", messageID, err)
+		}
+
+		return nil
+	}
+
+	if p.Current() == nil {
+		return nil
+	}
+
+	send := discordgo.MessageSend{Embeds: embeds, Components: components}
+	message, err := p.manager.Session.ChannelMessageSendComplex(channelID, &send, discord.RequestOptions(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to send now playing message: %s", err)
+	}
+
+	p.mutex.Lock()
+	p.nowPlayingMessageID = message.ID
+	p.mutex.Unlock()
+
+	return nil
+}
+
+// finishNowPlaying leaves the now playing message in its idle state, the next
+// track to play will post a fresh one
+func (p *Player) finishNowPlaying(ctx context.Context) {
+	if err := p.refreshNowPlaying(ctx); err != nil {
+		p.log.Warn().Err(err).Send()
+	}
+
+	p.mutex.Lock()
+	p.nowPlayingMessageID = ""
+	p.mutex.Unlock()
+}
+
+// watchNowPlaying periodically refreshes the progress on the now playing
+// message while a track is playing
+func (p *Player) watchNowPlaying(ctx context.Context) {
+	ticker := time.NewTicker(nowPlayingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if p.Current() == nil || p.Paused() {
+			continue
+		}
+
+		if err := p.refreshNowPlaying(ctx); err != nil {
+			p.log.Warn().Err(err).Send()
+		}
+	}
+}
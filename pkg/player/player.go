@@ -0,0 +1,762 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/audio"
+	"github.com/axatol/guosheng/pkg/cache"
+	"github.com/axatol/guosheng/pkg/cli"
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type Player struct {
+	manager             *Manager
+	guildID             string
+	mutex               sync.RWMutex
+	channelID           string
+	textChannelID       string
+	nowPlayingMessageID string
+	current             *Track
+	chapters            []Chapter
+	segments            []Segment
+	skipVotes           map[string]bool
+	skipVotesTrack      *Track
+	skipVotesNeeded     int
+	queue               []*Track
+	notify              chan struct{}
+	clip                audio.FrameReader
+	clipNotify          chan struct{}
+	cancel              context.CancelFunc
+	done                chan struct{}
+	paused              bool
+	unpause             chan struct{}
+	seek                *time.Duration
+	reload              bool
+	reconnect           bool
+	loop                LoopMode
+	autoplay            bool
+	recent              []string
+	skipped             bool
+	startedAt           time.Time
+	stopped             bool
+	settings            Settings
+	elapsed             atomic.Int64
+	aloneTimer          *time.Timer
+	idleTimer           *time.Timer
+	log                 zerolog.Logger
+}
+
+func newPlayer(manager *Manager, guildID string) *Player {
+	return &Player{
+		manager: manager,
+		guildID: guildID,
+		loop:    LoopOff,
+		notify:  make(chan struct{}, 1),
+		// clips can interrupt playback even while paused
+		clipNotify: make(chan struct{}, 1),
+		log:        log.With().Str("guild_id", guildID).Logger(),
+	}
+}
+
+func (p *Player) GuildID() string {
+	return p.guildID
+}
+
+func (p *Player) ChannelID() string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.channelID
+}
+
+func (p *Player) Current() *Track {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.current
+}
+
+func (p *Player) Paused() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.paused
+}
+
+func (p *Player) Settings() Settings {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.settings
+}
+
+// updateSettings persists the changed settings and reloads the current track
+// if needed so the change is audible straight away
+func (p *Player) updateSettings(ctx context.Context, update func(*Settings) error) error {
+	p.mutex.Lock()
+	settings := p.settings
+	if err := update(&settings); err != nil {
+		p.mutex.Unlock()
+		return err
+	}
+
+	reload := !slices.Equal(p.settings.AudioFilters(), settings.AudioFilters())
+	p.settings = settings
+	p.reload = p.cancel != nil && reload
+	p.mutex.Unlock()
+
+	return p.manager.saveSettings(ctx, p.guildID, settings)
+}
+
+func (p *Player) SetVolume(ctx context.Context, volume int) error {
+	if volume < 0 || volume > MaxVolume {
+		return fmt.Errorf("volume must be between 0 and %d, got: %d", MaxVolume, volume)
+	}
+
+	return p.updateSettings(ctx, func(s *Settings) error {
+		s.Volume = volume
+		return nil
+	})
+}
+
+// AddFilter adds the filter to the stack, replacing any filter of the same name
+func (p *Player) AddFilter(ctx context.Context, spec string) error {
+	if _, err := cli.ParseFilter(spec); err != nil {
+		return err
+	}
+
+	return p.updateSettings(ctx, func(s *Settings) error {
+		name, _, _ := strings.Cut(spec, ":")
+		filters := removeFilter(s.Filters, name)
+		if len(filters) >= MaxFilters {
+			return fmt.Errorf("at most %d filters can be applied at once", MaxFilters)
+		}
+
+		s.Filters = append(filters, spec)
+		return nil
+	})
+}
+
+func (p *Player) RemoveFilter(ctx context.Context, name string) error {
+	return p.updateSettings(ctx, func(s *Settings) error {
+		s.Filters = removeFilter(s.Filters, name)
+		return nil
+	})
+}
+
+func (p *Player) ClearFilters(ctx context.Context) error {
+	return p.updateSettings(ctx, func(s *Settings) error {
+		s.Filters = nil
+		return nil
+	})
+}
+
+func removeFilter(filters []string, name string) []string {
+	var result []string
+	for _, spec := range filters {
+		if existing, _, _ := strings.Cut(spec, ":"); !strings.EqualFold(existing, name) {
+			result = append(result, spec)
+		}
+	}
+
+	return result
+}
+
+// Position returns how far into the current track playback is
+func (p *Player) Position() time.Duration {
+	return time.Duration(p.elapsed.Load()) * audio.FrameDuration
+}
+
+// Queue returns a copy of the tracks waiting to be played
+func (p *Player) Queue() []*Track {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	queue := make([]*Track, len(p.queue))
+	copy(queue, p.queue)
+	return queue
+}
+
+// Enqueue appends tracks to the end of the queue and returns the position of
+// the first one, the voice channel is only switched if nothing is playing
+func (p *Player) Enqueue(channelID string, tracks ...*Track) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.current == nil || p.channelID == "" {
+		p.channelID = channelID
+	}
+
+	position := len(p.queue) + 1
+	if p.current == nil {
+		position -= 1
+	}
+
+	p.queue = append(p.queue, tracks...)
+	p.signal()
+
+	return position
+}
+
+// Dequeue removes and returns the track at the head of the queue
+func (p *Player) Dequeue() *Track {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.queue) < 1 {
+		return nil
+	}
+
+	track := p.queue[0]
+	p.queue = p.queue[1:]
+	return track
+}
+
+// Skip stops the current track, the next track in the queue will be played
+func (p *Player) Skip() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.skip()
+}
+
+func (p *Player) skip() bool {
+	if p.cancel == nil {
+		return false
+	}
+
+	p.skipped = true
+	p.cancel()
+	p.resume()
+	return true
+}
+
+// Stop clears the queue, stops the current track and leaves the voice channel
+func (p *Player) Stop() error {
+	p.mutex.Lock()
+	p.queue = nil
+	done := p.done
+	if p.cancel != nil {
+		p.stopped = true
+		p.cancel()
+		p.resume()
+	}
+
+	p.mutex.Unlock()
+
+	// wait for playback to wind down before pulling the connection from under it
+	if done != nil {
+		<-done
+	}
+
+	return p.disconnect()
+}
+
+// Seek moves playback of the current track to the given position
+func (p *Player) Seek(position time.Duration) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.cancel == nil || p.current == nil || p.current.Live {
+		return false
+	}
+
+	p.seek = &position
+	return true
+}
+
+func (p *Player) takeSeek() (time.Duration, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.seek == nil {
+		return 0, false
+	}
+
+	position := *p.seek
+	p.seek = nil
+	return position, true
+}
+
+func (p *Player) takeReload() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	reload := p.reload
+	p.reload = false
+	return reload
+}
+
+func (p *Player) Pause() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.cancel == nil || p.paused {
+		return false
+	}
+
+	p.paused = true
+	p.unpause = make(chan struct{})
+	return true
+}
+
+func (p *Player) Resume() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.resume()
+}
+
+func (p *Player) resume() bool {
+	if !p.paused {
+		return false
+	}
+
+	p.paused = false
+	close(p.unpause)
+	return true
+}
+
+// waitIfPaused returns a channel that is closed once playback is resumed, or
+// nil if playback is not paused
+func (p *Player) waitIfPaused() <-chan struct{} {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if !p.paused {
+		return nil
+	}
+
+	return p.unpause
+}
+
+func (p *Player) signal() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next advances the queue after the previous track has ended, which is nil if
+// nothing has been played yet
+func (p *Player) next(previous *Track, failed bool) *Track {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.requeue(previous, failed)
+	p.remember(previous)
+	p.skipped = false
+	p.stopped = false
+	p.current = nil
+
+	if len(p.queue) > 0 {
+		p.current = p.queue[0]
+		p.queue = p.queue[1:]
+	}
+
+	return p.current
+}
+
+func (p *Player) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.notify:
+		}
+
+		p.cancelIdleDisconnect()
+		p.playIdleClip(ctx)
+
+		for track := p.next(nil, false); track != nil && ctx.Err() == nil; {
+			err := p.play(ctx, track)
+			if err != nil {
+				p.log.Error().Err(err).Str("track_id", track.ID).Send()
+			}
+
+			p.record(ctx, track, err)
+
+			previous := track
+			autoplay := err == nil && p.shouldAutoplay()
+			if track = p.next(previous, err != nil); track == nil && autoplay {
+				track = p.recommend(ctx, previous)
+			}
+		}
+
+		p.finishNowPlaying(ctx)
+		p.scheduleIdleDisconnect()
+	}
+}
+
+func (p *Player) disconnect() error {
+	p.stopTimer(&p.aloneTimer)
+	p.stopTimer(&p.idleTimer)
+
+	p.mutex.Lock()
+	p.channelID = ""
+	p.mutex.Unlock()
+
+	p.manager.Session.RLock()
+	vc, ok := p.manager.Session.VoiceConnections[p.guildID]
+	p.manager.Session.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	if err := vc.Disconnect(); err != nil {
+		return fmt.Errorf("failed to leave voice channel %s: %s", vc.ChannelID, err)
+	}
+
+	return nil
+}
+
+func (p *Player) join() (*discordgo.VoiceConnection, error) {
+	channelID := p.ChannelID()
+	if channelID == "" {
+		return nil, fmt.Errorf("no voice channel set for guild %s", p.guildID)
+	}
+
+	vc, err := p.manager.Session.ChannelVoiceJoin(p.guildID, channelID, false, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join voice channel %s: %s", channelID, err)
+	}
+
+	return vc, nil
+}
+
+// open streams the track from the cache if it has been downloaded before,
+// otherwise it is streamed from its source and cached while it is being played
+//
+// streams that are encoded start from the start position, demuxed streams
+// always start from the beginning as skipping their frames is cheap, the
+// offset of the returned stream says which one it is
+func (p *Player) open(ctx context.Context, track *Track, start time.Duration) (*trackStream, error) {
+	if track.Live {
+		return p.openLive(ctx, track)
+	}
+
+	var source io.ReadCloser
+	cacheKey := track.CacheKey()
+	cached, err := p.manager.ObjectStore.GetStream(ctx, cacheKey)
+	switch err {
+	case nil:
+		source = cached
+	case cache.ErrObjectNotFound:
+		origin, err := p.manager.source(track.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		// resuming part way through is left to ffmpeg rather than downloading
+		// everything before it again
+		if live, ok := origin.(LiveSource); ok && start > 0 {
+			return p.openURL(ctx, track, live, start)
+		}
+
+		download, err := origin.Open(ctx, track)
+		if err != nil {
+			return nil, err
+		}
+
+		source = newCacheTee(ctx, p.manager.ObjectStore, cacheKey, track.ToMap(), download)
+	default:
+		return nil, err
+	}
+
+	// opus sources can be sent as is unless they need to be filtered, anything
+	// else has to be re-encoded
+	probe := newProbeReader(source)
+	settings := p.Settings()
+	filters := settings.AudioFilters()
+	if len(filters) < 1 {
+		frames, err := audio.NewOpusReader(probe)
+		if err == nil {
+			probe.Commit()
+			stream := trackStream{
+				FrameReader: frames,
+				closers:     []io.Closer{source},
+				tempo:       1,
+			}
+
+			return &stream, nil
+		}
+
+		p.log.Debug().Err(err).Str("track_id", track.ID).Msg("could not demux source, encoding instead")
+	}
+
+	encoded, err := p.manager.CLI.Encode(ctx, track.ID, probe.Rewind(), start, filters...)
+	if err != nil {
+		source.Close()
+		return nil, err
+	}
+
+	// the source must be closed first to unblock the encoder reading from it
+	stream := trackStream{
+		FrameReader: audio.NewDCAReader(encoded),
+		closers:     []io.Closer{source, encoded},
+		offset:      int64(audio.FramesFor(start)),
+		encoded:     true,
+		tempo:       settings.Tempo(),
+	}
+
+	return &stream, nil
+}
+
+// openURL encodes the track from the start position by having ffmpeg read
+// the url of the source directly, the track is not cached
+func (p *Player) openURL(ctx context.Context, track *Track, live LiveSource, start time.Duration) (*trackStream, error) {
+	url, err := live.LiveURL(ctx, track)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := p.Settings()
+	encoded, err := p.manager.CLI.EncodeURL(ctx, track.ID, url, start, settings.AudioFilters()...)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := trackStream{
+		FrameReader: audio.NewDCAReader(encoded),
+		closers:     []io.Closer{encoded},
+		offset:      int64(audio.FramesFor(start)),
+		encoded:     true,
+		tempo:       settings.Tempo(),
+	}
+
+	return &stream, nil
+}
+
+// openLive streams the track straight from its source, bypassing the cache
+func (p *Player) openLive(ctx context.Context, track *Track) (*trackStream, error) {
+	origin, err := p.manager.source(track.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	live, ok := origin.(LiveSource)
+	if !ok {
+		return nil, fmt.Errorf("source %s cannot play live tracks", origin.Name())
+	}
+
+	url, err := live.LiveURL(ctx, track)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := p.Settings()
+	encoded, err := p.manager.CLI.StreamLive(ctx, url, settings.AudioFilters()...)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := trackStream{
+		FrameReader: audio.NewDCAReader(encoded),
+		closers:     []io.Closer{encoded},
+		encoded:     true,
+		tempo:       settings.Tempo(),
+	}
+
+	return &stream, nil
+}
+
+func (p *Player) play(ctx context.Context, track *Track) error {
+	p.log.Info().Str("track_id", track.ID).Str("track_title", track.Title).Msg("playing track")
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	p.mutex.Lock()
+	p.cancel = cancel
+	p.done = done
+	p.seek = nil
+	p.chapters = nil
+	p.segments = nil
+	p.startedAt = time.Time{}
+	p.skipVotes = nil
+	p.skipVotesTrack = nil
+	p.skipVotesNeeded = 0
+	p.mutex.Unlock()
+	p.elapsed.Store(0)
+
+	defer func() {
+		p.mutex.Lock()
+		p.cancel = nil
+		p.done = nil
+		p.paused = false
+		p.mutex.Unlock()
+
+		cancel()
+		close(done)
+	}()
+
+	vc, err := p.join()
+	if err != nil {
+		return err
+	}
+
+	stream, err := p.open(ctx, track, track.Start)
+	if err != nil {
+		return err
+	}
+
+	go p.loadChapters(ctx, track)
+	go p.loadSegments(ctx, track)
+
+	defer func() {
+		// the stream may have been reopened by a seek
+		if err := stream.Close(); err != nil {
+			p.log.Error().Err(fmt.Errorf("failed to close stream: %s", err)).Send()
+		}
+	}()
+
+	if err := p.refreshNowPlaying(ctx); err != nil {
+		p.log.Warn().Err(err).Send()
+	}
+
+	if err := vc.Speaking(true); err != nil {
+		return fmt.Errorf("failed to start speaking: %s", err)
+	}
+
+	defer func() {
+		if err := vc.Speaking(false); err != nil {
+			p.log.Error().Err(fmt.Errorf("failed to stop speaking: %s", err)).Send()
+		}
+	}()
+
+	position := stream.offset
+	if start := int64(audio.FramesFor(track.Start)); start > position {
+		if stream, position, err = p.seekStream(ctx, track, stream, position, int(start), false); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+	}
+
+	started := false
+	for {
+		if clip := p.takeClip(); clip != nil {
+			if vc, position, err = p.overlayClip(ctx, vc, track, stream, position, clip); err != nil {
+				if err == io.EOF || ctx.Err() != nil {
+					return nil
+				}
+
+				return err
+			}
+		}
+
+		if unpause := p.waitIfPaused(); unpause != nil {
+			if err := vc.Speaking(false); err != nil {
+				p.log.Error().Err(fmt.Errorf("failed to stop speaking: %s", err)).Send()
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-unpause:
+			case <-p.clipNotify:
+			}
+
+			if err := vc.Speaking(true); err != nil {
+				p.log.Error().Err(fmt.Errorf("failed to start speaking: %s", err)).Send()
+			}
+
+			// paused again once the clip is done
+			continue
+		}
+
+		target, seek := p.takeSeek()
+		if !seek {
+			target, seek = p.skipSegment(p.Position())
+		}
+
+		reload := p.takeReload()
+		if seek || reload {
+			frames := int(position)
+			if seek {
+				frames = audio.FramesFor(target)
+			}
+
+			if stream, position, err = p.seekStream(ctx, track, stream, position, frames, reload); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+
+				return err
+			}
+		}
+
+		frame, err := stream.ReadFrame()
+		if err != nil {
+			// stopping kills the stream from under the reader
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		if vc, err = p.send(ctx, vc, frame); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		if !started {
+			started = true
+			p.recordStart(ctx, track)
+		}
+
+		position = stream.position()
+		p.elapsed.Store(position)
+	}
+}
+
+// seekStream skips ahead to the target frame, frames are a fixed length so
+// seeking a demuxed stream is a matter of counting them, seeking backwards or
+// seeking an encoded stream reopens the track at the target
+func (p *Player) seekStream(ctx context.Context, track *Track, stream *trackStream, position int64, target int, reopen bool) (*trackStream, int64, error) {
+	// live streams cannot be rewound, reopening picks up from the live edge
+	if track.Live {
+		if err := stream.Close(); err != nil {
+			p.log.Warn().Err(fmt.Errorf("failed to close stream: %s", err)).Send()
+		}
+
+		reopened, err := p.openLive(ctx, track)
+		if err != nil {
+			return stream, position, err
+		}
+
+		return reopened, position, nil
+	}
+
+	if reopen || stream.encoded || int64(target) < position {
+		if err := stream.Close(); err != nil {
+			p.log.Warn().Err(fmt.Errorf("failed to close stream: %s", err)).Send()
+		}
+
+		reopened, err := p.open(ctx, track, time.Duration(target)*audio.FrameDuration)
+		if err != nil {
+			return stream, position, err
+		}
+
+		stream = reopened
+		position = reopened.offset
+	}
+
+	if err := audio.SkipFrames(stream, target-int(position)); err != nil {
+		return stream, stream.position(), err
+	}
+
+	position = stream.position()
+	p.elapsed.Store(position)
+	return stream, position, nil
+}
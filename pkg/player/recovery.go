@@ -0,0 +1,127 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// how long a frame may wait to be sent before the connection is considered dropped
+	voiceSendTimeout = time.Second * 5
+	// how long to wait for a connection to become ready after it was reopened
+	voiceReadyTimeout   = time.Second * 10
+	voiceRejoinAttempts = 5
+)
+
+// OnVoiceReconnect flags the player so the next frame sent re-establishes the
+// speaking state on the reopened connection
+func (m *Manager) OnVoiceReconnect(guildID string) {
+	if player, ok := m.Lookup(guildID); ok {
+		player.mutex.Lock()
+		player.reconnect = true
+		player.mutex.Unlock()
+	}
+}
+
+func (p *Player) takeReconnect() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	reconnect := p.reconnect
+	p.reconnect = false
+	return reconnect
+}
+
+// send delivers the frame to the voice connection, if the connection was
+// reopened or stops accepting frames it is recovered and the same frame is
+// sent again so playback resumes where it left off
+func (p *Player) send(ctx context.Context, vc *discordgo.VoiceConnection, frame []byte) (*discordgo.VoiceConnection, error) {
+	for {
+		if p.takeReconnect() {
+			p.log.Info().Msg("voice connection was reopened, resuming playback")
+			if waitUntilReady(ctx, vc) {
+				if err := vc.Speaking(true); err != nil {
+					p.log.Warn().Err(fmt.Errorf("failed to start speaking: %s", err)).Send()
+				}
+			}
+		}
+
+		timeout := time.NewTimer(voiceSendTimeout)
+		select {
+		case <-ctx.Done():
+			timeout.Stop()
+			return vc, ctx.Err()
+		case vc.OpusSend <- frame:
+			timeout.Stop()
+			return vc, nil
+		case <-timeout.C:
+		}
+
+		p.log.Warn().Str("channel_id", p.ChannelID()).Msg("voice connection stalled, rejoining")
+
+		rejoined, err := p.rejoin(ctx)
+		if err != nil {
+			return vc, err
+		}
+
+		vc = rejoined
+	}
+}
+
+// rejoin joins the voice channel again with backoff
+func (p *Player) rejoin(ctx context.Context) (*discordgo.VoiceConnection, error) {
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		vc, err := p.join()
+		if err == nil {
+			if err := vc.Speaking(true); err != nil {
+				return nil, fmt.Errorf("failed to start speaking: %s", err)
+			}
+
+			return vc, nil
+		}
+
+		if attempt >= voiceRejoinAttempts {
+			return nil, fmt.Errorf("failed to rejoin voice channel after %d attempts: %s", attempt, err)
+		}
+
+		p.log.Warn().Err(err).Int("attempt", attempt).Send()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+func waitUntilReady(ctx context.Context, vc *discordgo.VoiceConnection) bool {
+	ticker := time.NewTicker(time.Millisecond * 100)
+	defer ticker.Stop()
+
+	deadline := time.NewTimer(voiceReadyTimeout)
+	defer deadline.Stop()
+
+	for {
+		vc.RLock()
+		ready := vc.Ready
+		vc.RUnlock()
+
+		if ready {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-deadline.C:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/audio"
+)
+
+// categories of segments that can be skipped, as named by sponsorblock
+var SegmentCategories = []string{
+	"sponsor",
+	"selfpromo",
+	"interaction",
+	"intro",
+	"outro",
+	"preview",
+	"music_offtopic",
+	"filler",
+}
+
+var DefaultSkipCategories = []string{"sponsor", "music_offtopic"}
+
+// Segment is a part of a track that can be skipped over
+type Segment struct {
+	Category string        `json:"category"`
+	Start    time.Duration `json:"start"`
+	End      time.Duration `json:"end"`
+}
+
+// SegmentProvider looks up the skippable segments of a track in every
+// category, which ones are skipped is decided by the guild settings
+type SegmentProvider interface {
+	Segments(ctx context.Context, track *Track) ([]Segment, error)
+}
+
+func (p *Player) SetSkipCategory(ctx context.Context, category string, enabled bool) error {
+	if !slices.Contains(SegmentCategories, category) {
+		return fmt.Errorf("unknown segment category %s", category)
+	}
+
+	return p.updateSettings(ctx, func(s *Settings) error {
+		categories := slices.DeleteFunc(slices.Clone(s.SkipCategories), func(existing string) bool {
+			return existing == category
+		})
+
+		if enabled {
+			categories = append(categories, category)
+		}
+
+		s.SkipCategories = categories
+		return nil
+	})
+}
+
+// loadSegments looks up the segments of the track in the background so
+// playback does not wait on it
+func (p *Player) loadSegments(ctx context.Context, track *Track) {
+	if p.manager.Segments == nil || track.Live {
+		return
+	}
+
+	segments, err := p.manager.Segments.Segments(ctx, track)
+	if err != nil {
+		p.log.Warn().Err(err).Str("track_id", track.ID).Msg("failed to load segments")
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// playback may have moved on while looking them up
+	if p.current == track && ctx.Err() == nil {
+		p.segments = segments
+	}
+}
+
+// skipSegment returns where to resume playback if the position falls within a
+// segment the guild has chosen to skip
+func (p *Player) skipSegment(position time.Duration) (time.Duration, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for _, segment := range p.segments {
+		if position < segment.Start || position >= segment.End {
+			continue
+		}
+
+		if !slices.Contains(p.settings.SkipCategories, segment.Category) {
+			continue
+		}
+
+		p.log.Debug().
+			Str("category", segment.Category).
+			Dur("start", segment.Start).
+			Dur("end", segment.End).
+			Msg("skipping segment")
+
+		// resuming at a frame that starts before the end would land back in
+		// the segment, so playback resumes at the first frame after it
+		return (segment.End + audio.FrameDuration - 1).Truncate(audio.FrameDuration), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,49 @@
+package player
+
+import (
+	"testing"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/audio"
+)
+
+func TestSkipSegment(t *testing.T) {
+	p := newPlayer(&Manager{}, "guild")
+	p.settings = defaultSettings()
+	p.settings.SkipCategories = []string{"sponsor"}
+	p.segments = []Segment{
+		{Category: "sponsor", Start: 10 * time.Second, End: 20*time.Second + 7*time.Millisecond},
+		{Category: "intro", Start: 0, End: 5 * time.Second},
+		{Category: "sponsor", Start: 30 * time.Second, End: 40 * time.Second},
+	}
+
+	tests := []struct {
+		name     string
+		position time.Duration
+		target   time.Duration
+		skip     bool
+	}{
+		{name: "before", position: 9 * time.Second},
+		{name: "unaligned end rounds up", position: 15 * time.Second, target: 20*time.Second + 20*time.Millisecond, skip: true},
+		{name: "aligned end", position: 30 * time.Second, target: 40 * time.Second, skip: true},
+		{name: "category not skipped", position: 2 * time.Second},
+		{name: "at end", position: 40 * time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			target, skip := p.skipSegment(test.position)
+			if skip != test.skip || target != test.target {
+				t.Fatalf("expected %v %v, got %v %v", test.target, test.skip, target, skip)
+			}
+
+			// resuming at the target must not land back in the segment
+			if skip {
+				resumed := time.Duration(audio.FramesFor(target)) * audio.FrameDuration
+				if _, again := p.skipSegment(resumed); again {
+					t.Fatalf("resuming at %v skips again", resumed)
+				}
+			}
+		})
+	}
+}
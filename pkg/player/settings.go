@@ -0,0 +1,108 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/axatol/guosheng/pkg/cache"
+	"github.com/axatol/guosheng/pkg/cli"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultVolume = 100
+	MaxVolume     = 200
+	MaxFilters    = 5
+)
+
+// Settings are the per-guild preferences that outlive a player
+type Settings struct {
+	Volume         int      `json:"volume"`
+	Filters        []string `json:"filters"`
+	SkipCategories []string `json:"skip_categories"`
+}
+
+func defaultSettings() Settings {
+	return Settings{
+		Volume:         DefaultVolume,
+		SkipCategories: DefaultSkipCategories,
+	}
+}
+
+// AudioFilters returns the ffmpeg audio filters needed to apply the settings
+func (s Settings) AudioFilters() []string {
+	var filters []string
+	for _, spec := range s.Filters {
+		filter, err := cli.ParseFilter(spec)
+		if err != nil {
+			log.Warn().Err(err).Str("filter", spec).Msg("ignoring invalid filter")
+			continue
+		}
+
+		filters = append(filters, filter)
+	}
+
+	if s.Volume != DefaultVolume {
+		filters = append(filters, fmt.Sprintf("volume=%.2f", float64(s.Volume)/100))
+	}
+
+	return filters
+}
+
+// Tempo returns the factor the filters speed playback up by, positions in the
+// output have to be scaled by it to get back to positions in the track
+func (s Settings) Tempo() float64 {
+	tempo := 1.0
+	for _, spec := range s.Filters {
+		if _, err := cli.ParseFilter(spec); err == nil {
+			tempo *= cli.FilterTempo(spec)
+		}
+	}
+
+	return tempo
+}
+
+// Settings returns the settings of the guild without starting a player for it
+func (m *Manager) Settings(ctx context.Context, guildID string) (Settings, error) {
+	if player, ok := m.Lookup(guildID); ok {
+		return player.Settings(), nil
+	}
+
+	return m.loadSettings(ctx, guildID)
+}
+
+func settingsKey(guildID string) string {
+	return fmt.Sprintf("guilds/%s/settings.json", guildID)
+}
+
+func (m *Manager) loadSettings(ctx context.Context, guildID string) (Settings, error) {
+	settings := defaultSettings()
+	raw, err := m.ObjectStore.Get(ctx, settingsKey(guildID))
+	if err != nil {
+		if err == cache.ErrObjectNotFound {
+			return settings, nil
+		}
+
+		return settings, fmt.Errorf("failed to load settings for guild %s: %s", guildID, err)
+	}
+
+	if err := json.Unmarshal(raw, &settings); err != nil {
+		return settings, fmt.Errorf("failed to parse settings for guild %s: %s", guildID, err)
+	}
+
+	return settings, nil
+}
+
+func (m *Manager) saveSettings(ctx context.Context, guildID string, settings Settings) error {
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings for guild %s: %s", guildID, err)
+	}
+
+	if _, err := m.ObjectStore.Put(ctx, settingsKey(guildID), raw, nil); err != nil {
+		return fmt.Errorf("failed to save settings for guild %s: %s", guildID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/cache"
+	"github.com/rs/zerolog/log"
+)
+
+// Snapshot is the state of a player saved so it can pick up where it left
+// off after a restart
+type Snapshot struct {
+	GuildID       string    `json:"guild_id"`
+	ChannelID     string    `json:"channel_id"`
+	TextChannelID string    `json:"text_channel_id"`
+	Current       *Track    `json:"current"`
+	Queue         []*Track  `json:"queue"`
+	Loop          LoopMode  `json:"loop"`
+	Autoplay      bool      `json:"autoplay"`
+	SavedAt       time.Time `json:"saved_at"`
+}
+
+func snapshotKey(guildID string) string {
+	return fmt.Sprintf("guilds/%s/snapshot.json", guildID)
+}
+
+// snapshot captures the player state, the current track is saved to start
+// from where it is now
+func (p *Player) snapshot() Snapshot {
+	position := p.Position()
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	snapshot := Snapshot{
+		GuildID:       p.guildID,
+		ChannelID:     p.channelID,
+		TextChannelID: p.textChannelID,
+		Queue:         append([]*Track{}, p.queue...),
+		Loop:          p.loop,
+		Autoplay:      p.autoplay,
+		SavedAt:       time.Now(),
+	}
+
+	if p.current != nil {
+		current := *p.current
+		if !current.Live {
+			current.Start = position
+		}
+
+		snapshot.Current = &current
+	}
+
+	return snapshot
+}
+
+// restore queues up the tracks from the snapshot, which rejoins the voice
+// channel and resumes playback
+func (p *Player) restore(snapshot Snapshot) {
+	p.mutex.Lock()
+	p.loop = snapshot.Loop
+	p.autoplay = snapshot.Autoplay
+	p.mutex.Unlock()
+
+	p.SetTextChannel(snapshot.TextChannelID)
+
+	var tracks []*Track
+	if snapshot.Current != nil {
+		tracks = append(tracks, snapshot.Current)
+	}
+
+	tracks = append(tracks, snapshot.Queue...)
+	if len(tracks) > 0 && snapshot.ChannelID != "" {
+		p.Enqueue(snapshot.ChannelID, tracks...)
+	}
+}
+
+func (m *Manager) saveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot for guild %s: %s", snapshot.GuildID, err)
+	}
+
+	if _, err := m.ObjectStore.Put(ctx, snapshotKey(snapshot.GuildID), raw, nil); err != nil {
+		return fmt.Errorf("failed to save snapshot for guild %s: %s", snapshot.GuildID, err)
+	}
+
+	return nil
+}
+
+func (m *Manager) loadSnapshot(ctx context.Context, guildID string) (*Snapshot, error) {
+	raw, err := m.ObjectStore.Get(ctx, snapshotKey(guildID))
+	if err != nil {
+		if err == cache.ErrObjectNotFound {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to load snapshot for guild %s: %s", guildID, err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot for guild %s: %s", guildID, err)
+	}
+
+	return &snapshot, nil
+}
+
+// SaveSnapshots saves the state of every player
+func (m *Manager) SaveSnapshots(ctx context.Context) error {
+	m.snapshotMutex.Lock()
+	defer m.snapshotMutex.Unlock()
+
+	if m.closed {
+		return nil
+	}
+
+	return m.saveSnapshots(ctx, m.snapshots())
+}
+
+func (m *Manager) snapshots() []Snapshot {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snapshots := make([]Snapshot, 0, len(m.players))
+	for _, player := range m.players {
+		snapshots = append(snapshots, player.snapshot())
+	}
+
+	return snapshots
+}
+
+func (m *Manager) saveSnapshots(ctx context.Context, snapshots []Snapshot) error {
+	var failed int
+	for _, snapshot := range snapshots {
+		if err := m.saveSnapshot(ctx, snapshot); err != nil {
+			log.Warn().Err(err).Send()
+			failed += 1
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to save %d of %d snapshots", failed, len(snapshots))
+	}
+
+	return nil
+}
+
+// RestoreSnapshots resumes the players of the guilds that were playing when
+// the snapshots were last saved, snapshots are cleared once they are used so
+// they are not restored twice
+func (m *Manager) RestoreSnapshots(ctx context.Context, guildIDs []string) {
+	for _, guildID := range guildIDs {
+		snapshot, err := m.loadSnapshot(ctx, guildID)
+		if err != nil {
+			log.Warn().Err(err).Send()
+			continue
+		}
+
+		if snapshot == nil || (snapshot.Current == nil && len(snapshot.Queue) < 1) {
+			continue
+		}
+
+		// nobody is waiting for a queue from long ago
+		if m.SnapshotMaxAge > 0 && time.Since(snapshot.SavedAt) > m.SnapshotMaxAge {
+			log.Info().
+				Str("guild_id", guildID).
+				Time("saved_at", snapshot.SavedAt).
+				Msg("discarding stale snapshot")
+		} else {
+			log.Info().
+				Str("guild_id", guildID).
+				Int("queue_length", len(snapshot.Queue)).
+				Time("saved_at", snapshot.SavedAt).
+				Msg("restoring player")
+
+			m.Get(guildID).restore(*snapshot)
+		}
+
+		if err := m.saveSnapshot(ctx, Snapshot{GuildID: guildID, SavedAt: time.Now()}); err != nil {
+			log.Warn().Err(err).Send()
+		}
+	}
+}
+
+// watchSnapshots periodically saves the state of every player so a crash
+// loses little
+func (m *Manager) watchSnapshots() {
+	if m.SnapshotInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.SnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := m.SaveSnapshots(m.ctx); err != nil {
+			log.Warn().Err(err).Send()
+		}
+	}
+}
+
+// Close saves the state of every player then stops them, the state is taken
+// first so stopping does not disturb it
+func (m *Manager) Close(ctx context.Context) error {
+	m.snapshotMutex.Lock()
+	defer m.snapshotMutex.Unlock()
+
+	if m.closed {
+		return nil
+	}
+
+	m.closed = true
+	snapshots := m.snapshots()
+	m.cancel()
+
+	return m.saveSnapshots(ctx, snapshots)
+}
@@ -0,0 +1,85 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/axatol/guosheng/pkg/cache"
+)
+
+// tracks saved before sources existed are all youtube videos
+const DefaultSource = "youtube"
+
+// Source resolves user input to tracks and streams their audio
+type Source interface {
+	Name() string
+	// Match reports whether the input should be resolved by this source
+	Match(input string) bool
+	Resolve(ctx context.Context, input string) ([]*Track, error)
+	// Open streams the audio of the track in any format ffmpeg can decode
+	Open(ctx context.Context, track *Track) (io.ReadCloser, error)
+}
+
+// LiveSource is implemented by sources that can play live tracks, ffmpeg reads
+// the returned url directly for as long as the stream lasts
+type LiveSource interface {
+	Source
+	LiveURL(ctx context.Context, track *Track) (string, error)
+}
+
+// Resolve finds the first source that matches the input and resolves it
+func (m *Manager) Resolve(ctx context.Context, input string) ([]*Track, error) {
+	for _, source := range m.Sources {
+		if !source.Match(input) {
+			continue
+		}
+
+		tracks, err := source.Resolve(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s with source %s: %s", input, source.Name(), err)
+		}
+
+		for _, track := range tracks {
+			track.Source = source.Name()
+		}
+
+		return tracks, nil
+	}
+
+	return nil, fmt.Errorf("no source can play %s", input)
+}
+
+func (m *Manager) source(name string) (Source, error) {
+	if name == "" {
+		name = DefaultSource
+	}
+
+	for _, source := range m.Sources {
+		if source.Name() == name {
+			return source, nil
+		}
+	}
+
+	return nil, fmt.Errorf("source %s is not available", name)
+}
+
+// Download streams the audio of the track from the cache if it has been played
+// before, otherwise straight from its source without caching it
+func (m *Manager) Download(ctx context.Context, track *Track) (io.ReadCloser, error) {
+	cached, err := m.ObjectStore.GetStream(ctx, track.CacheKey())
+	if err == nil {
+		return cached, nil
+	}
+
+	if err != cache.ErrObjectNotFound {
+		return nil, err
+	}
+
+	origin, err := m.source(track.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	return origin.Open(ctx, track)
+}
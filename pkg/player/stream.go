@@ -0,0 +1,142 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/axatol/guosheng/pkg/audio"
+	"github.com/axatol/guosheng/pkg/cache"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	errCacheIncomplete = errors.New("source was closed before it was fully read")
+)
+
+// trackStream is the frame reader for a track along with everything that must
+// be released once playback stops, closed in order
+type trackStream struct {
+	audio.FrameReader
+	closers []io.Closer
+	// the frame of the track the stream starts at
+	offset int64
+	// encoded streams are reopened to seek rather than skipping frames
+	encoded bool
+	// how many frames of the track each frame of the stream covers
+	tempo float64
+	read  int64
+	once  sync.Once
+	err   error
+}
+
+func (s *trackStream) ReadFrame() ([]byte, error) {
+	frame, err := s.FrameReader.ReadFrame()
+	if err == nil {
+		s.read += 1
+	}
+
+	return frame, err
+}
+
+// position returns the frame of the track the stream has been read up to,
+// which runs ahead of or behind the frames read if the tempo was changed
+func (s *trackStream) position() int64 {
+	return s.offset + int64(float64(s.read)*s.tempo)
+}
+
+func (s *trackStream) Close() error {
+	s.once.Do(func() {
+		for _, closer := range s.closers {
+			if err := closer.Close(); err != nil && s.err == nil {
+				s.err = err
+			}
+		}
+	})
+
+	return s.err
+}
+
+// cacheTee copies everything read from the source into the object store, the
+// object is only committed if the source is read to the end successfully
+type cacheTee struct {
+	source   io.ReadCloser
+	writer   *io.PipeWriter
+	writeErr error
+	done     chan error
+}
+
+func newCacheTee(ctx context.Context, store cache.ObjectStore, key string, tags map[string]string, source io.ReadCloser) *cacheTee {
+	reader, writer := io.Pipe()
+	tee := cacheTee{source: source, writer: writer, done: make(chan error, 1)}
+
+	go func() {
+		_, err := store.PutStream(ctx, key, reader, tags)
+		reader.CloseWithError(err)
+		tee.done <- err
+	}()
+
+	return &tee
+}
+
+func (t *cacheTee) Read(p []byte) (int, error) {
+	n, err := t.source.Read(p)
+	if n > 0 && t.writeErr == nil {
+		_, t.writeErr = t.writer.Write(p[:n])
+	}
+
+	if err == io.EOF {
+		if err := t.source.Close(); err != nil {
+			t.writer.CloseWithError(err)
+		} else {
+			t.writer.Close()
+		}
+	}
+
+	return n, err
+}
+
+func (t *cacheTee) Close() error {
+	t.writer.CloseWithError(errCacheIncomplete)
+	err := t.source.Close()
+
+	if cacheErr := <-t.done; cacheErr != nil {
+		log.Warn().Err(fmt.Errorf("failed to cache source: %s", cacheErr)).Send()
+	}
+
+	return err
+}
+
+// probeReader records everything read until it is committed, so the input can
+// be replayed to another consumer if probing it fails
+type probeReader struct {
+	reader    io.Reader
+	recorded  bytes.Buffer
+	recording bool
+}
+
+func newProbeReader(reader io.Reader) *probeReader {
+	return &probeReader{reader: reader, recording: true}
+}
+
+func (r *probeReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if r.recording {
+		r.recorded.Write(p[:n])
+	}
+
+	return n, err
+}
+
+func (r *probeReader) Commit() {
+	r.recording = false
+	r.recorded = bytes.Buffer{}
+}
+
+func (r *probeReader) Rewind() io.Reader {
+	r.recording = false
+	return io.MultiReader(&r.recorded, r.reader)
+}
@@ -0,0 +1,88 @@
+package player
+
+import (
+	"time"
+)
+
+// OnVoiceListeners leaves the voice channel once the bot has been alone in it
+// for the configured grace period, and cancels leaving if someone comes back
+func (m *Manager) OnVoiceListeners(guildID, channelID string, listeners int) {
+	player, ok := m.Lookup(guildID)
+	if !ok || player.ChannelID() != channelID {
+		return
+	}
+
+	if listeners > 0 {
+		player.stopTimer(&player.aloneTimer)
+		return
+	}
+
+	player.startTimer(&player.aloneTimer, m.AloneTimeout, func() {
+		player.log.Info().Str("channel_id", channelID).Msg("leaving empty voice channel")
+		if err := player.Stop(); err != nil {
+			player.log.Warn().Err(err).Send()
+		}
+	})
+}
+
+// scheduleIdleDisconnect leaves the voice channel if nothing starts playing
+// within the configured idle timeout
+func (p *Player) scheduleIdleDisconnect() {
+	p.startTimer(&p.idleTimer, p.manager.IdleTimeout, func() {
+		if p.Current() != nil || len(p.Queue()) > 0 {
+			return
+		}
+
+		p.log.Info().Msg("leaving idle voice channel")
+		if err := p.disconnect(); err != nil {
+			p.log.Warn().Err(err).Send()
+		}
+	})
+}
+
+func (p *Player) cancelIdleDisconnect() {
+	p.stopTimer(&p.idleTimer)
+}
+
+// startTimer schedules fn unless the timer is already running, a timeout of
+// zero disables it
+func (p *Player) startTimer(timer **time.Timer, timeout time.Duration, fn func()) {
+	if timeout <= 0 {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if *timer != nil {
+		return
+	}
+
+	// a timer that was replaced after it fired must not clear its replacement
+	var current *time.Timer
+	current = time.AfterFunc(timeout, func() {
+		p.mutex.Lock()
+		active := *timer == current
+		if active {
+			*timer = nil
+		}
+
+		p.mutex.Unlock()
+
+		if active {
+			fn()
+		}
+	})
+
+	*timer = current
+}
+
+func (p *Player) stopTimer(timer **time.Timer) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if *timer != nil {
+		(*timer).Stop()
+		*timer = nil
+	}
+}
@@ -0,0 +1,97 @@
+package player
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/discord"
+	"github.com/axatol/guosheng/pkg/util"
+	"github.com/axatol/guosheng/pkg/yt"
+)
+
+type Track struct {
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	URL         string        `json:"url"`
+	Uploader    string        `json:"uploader"`
+	UploaderURL string        `json:"uploader_url"`
+	Duration    time.Duration `json:"duration"`
+	Start       time.Duration `json:"start"`
+	RequesterID string        `json:"requester_id"`
+	Autoplay    bool          `json:"autoplay,omitempty"`
+	Source      string        `json:"source,omitempty"`
+	Live        bool          `json:"live,omitempty"`
+	Chapters    []Chapter     `json:"chapters,omitempty"`
+}
+
+func NewTrackFromVideo(video *yt.Video) *Track {
+	track := Track{
+		ID:          video.ID,
+		Title:       video.Title,
+		URL:         video.VideoURL(),
+		Uploader:    video.ChannelTitle,
+		UploaderURL: video.ChannelURL(),
+		Source:      DefaultSource,
+		Live:        video.IsLive(),
+	}
+
+	if duration := video.Duration(); duration != nil {
+		track.Duration = duration.Duration()
+	}
+
+	return &track
+}
+
+// CacheKey is where the audio of the track is kept in the object store, ids
+// are only unique within a source so the key includes it, youtube videos keep
+// the key they were cached under before there were other sources
+func (t *Track) CacheKey() string {
+	if t.Source == "" || t.Source == DefaultSource {
+		return fmt.Sprintf("cache/%s", t.ID)
+	}
+
+	return fmt.Sprintf("cache/%s/%s", t.Source, t.ID)
+}
+
+func (t *Track) ToMap() map[string]string {
+	return map[string]string{
+		"id":           t.ID,
+		"title":        t.Title,
+		"url":          t.URL,
+		"uploader":     t.Uploader,
+		"uploader_url": t.UploaderURL,
+		"duration":     t.Duration.String(),
+	}
+}
+
+func (t *Track) DurationString() string {
+	if t.Live {
+		return "🔴 LIVE"
+	}
+
+	if t.Duration <= 0 {
+		return "?"
+	}
+
+	return util.FormatDuration(t.Duration)
+}
+
+func (t *Track) MessageEmbed() *discord.MessageEmbed {
+	embed := discord.NewMessageEmbed().
+		SetTitle(t.Title).
+		SetURL(t.URL).
+		AddField("Uploader", util.MDLink(t.Uploader, t.UploaderURL)).
+		AddField("Duration", t.DurationString())
+
+	if t.Start > 0 {
+		embed.AddField("Starting at", util.FormatDuration(t.Start))
+	}
+
+	if t.RequesterID != "" {
+		embed.AddField("Requested by", fmt.Sprintf("<@%s>", t.RequesterID))
+	} else if t.Autoplay {
+		embed.AddField("Requested by", "autoplay")
+	}
+
+	return embed
+}
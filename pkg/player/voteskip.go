@@ -0,0 +1,85 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+)
+
+type SkipVote struct {
+	Skipped bool
+	Votes   int
+	Needed  int
+}
+
+// VoteSkip skips the current track straight away for djs, its requester or
+// when vote skipping is off, otherwise the vote is counted and the track is
+// only skipped once enough of the listeners agree
+func (p *Player) VoteSkip(ctx context.Context, userID string, listeners []string, dj bool) (*SkipVote, error) {
+	p.mutex.Lock()
+	current := p.current
+	if current == nil {
+		p.mutex.Unlock()
+		return nil, fmt.Errorf("nothing is playing")
+	}
+
+	if dj || p.manager.VoteSkipRatio <= 0 || current.RequesterID == userID {
+		skipped := p.skip()
+		p.mutex.Unlock()
+		if !skipped {
+			return nil, fmt.Errorf("nothing is playing")
+		}
+
+		return &SkipVote{Skipped: true}, nil
+	}
+
+	if !slices.Contains(listeners, userID) {
+		p.mutex.Unlock()
+		return nil, fmt.Errorf("must be listening to vote")
+	}
+
+	// votes only count towards the track they were cast for
+	if p.skipVotes == nil || p.skipVotesTrack != current {
+		p.skipVotes = map[string]bool{}
+		p.skipVotesTrack = current
+	}
+
+	p.skipVotes[userID] = true
+
+	// listeners who left since voting no longer count
+	for voter := range p.skipVotes {
+		if !slices.Contains(listeners, voter) {
+			delete(p.skipVotes, voter)
+		}
+	}
+
+	vote := SkipVote{
+		Votes:  len(p.skipVotes),
+		Needed: max(1, int(math.Ceil(p.manager.VoteSkipRatio*float64(len(listeners))))),
+	}
+
+	p.skipVotesNeeded = vote.Needed
+	if vote.Votes >= vote.Needed {
+		vote.Skipped = p.skip()
+	}
+
+	p.mutex.Unlock()
+
+	if vote.Skipped {
+		return &vote, nil
+	}
+
+	if err := p.refreshNowPlaying(ctx); err != nil {
+		p.log.Warn().Err(err).Send()
+	}
+
+	return &vote, nil
+}
+
+// SkipVotes returns the tally of votes to skip the current track
+func (p *Player) SkipVotes() (int, int) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return len(p.skipVotes), p.skipVotesNeeded
+}
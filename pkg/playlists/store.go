@@ -0,0 +1,302 @@
+package playlists
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/cache"
+	"github.com/axatol/guosheng/pkg/player"
+)
+
+const (
+	MaxPlaylists = 25
+	MaxTracks    = 500
+	PageSize     = 10
+)
+
+var (
+	ErrPlaylistNotFound = errors.New("playlist not found")
+	ErrPlaylistExists   = errors.New("playlist already exists")
+	ErrNotOwner         = errors.New("only the creator can change this playlist")
+
+	// names end up in custom ids so must not contain separators
+	validName = regexp.MustCompile(`^[\w\- ]{1,32}$`)
+)
+
+type Playlist struct {
+	Name    string `json:"name"`
+	OwnerID string `json:"owner_id"`
+	// set when the playlist is shared with everyone in the guild
+	GuildID   string          `json:"guild_id,omitempty"`
+	Tracks    []*player.Track `json:"tracks"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func (p *Playlist) Shared() bool {
+	return p.GuildID != ""
+}
+
+// Pages returns how many pages of PageSize tracks the playlist spans
+func (p *Playlist) Pages() int {
+	return max(1, (len(p.Tracks)+PageSize-1)/PageSize)
+}
+
+// Page returns the tracks on the zero indexed page
+func (p *Playlist) Page(page int) []*player.Track {
+	start := min(max(page, 0)*PageSize, len(p.Tracks))
+	end := min(start+PageSize, len(p.Tracks))
+	return p.Tracks[start:end]
+}
+
+func (p *Playlist) Duration() time.Duration {
+	var total time.Duration
+	for _, track := range p.Tracks {
+		total += track.Duration
+	}
+
+	return total
+}
+
+// Store keeps playlists in the object store, a document per user holds their
+// own playlists and a document per guild holds the shared ones
+type Store struct {
+	ObjectStore cache.ObjectStore
+	mutex       sync.Mutex
+}
+
+func userKey(userID string) string {
+	return fmt.Sprintf("users/%s/playlists.json", userID)
+}
+
+// scopeKeys returns the documents the user can see, outside of a guild there
+// are no shared playlists
+func scopeKeys(guildID, userID string) []string {
+	if guildID == "" {
+		return []string{userKey(userID)}
+	}
+
+	return []string{userKey(userID), guildKey(guildID)}
+}
+
+func guildKey(guildID string) string {
+	return fmt.Sprintf("guilds/%s/playlists.json", guildID)
+}
+
+func playlistKey(playlist *Playlist) string {
+	if playlist.Shared() {
+		return guildKey(playlist.GuildID)
+	}
+
+	return userKey(playlist.OwnerID)
+}
+
+func (s *Store) load(ctx context.Context, key string) (map[string]*Playlist, error) {
+	playlists := map[string]*Playlist{}
+	raw, err := s.ObjectStore.Get(ctx, key)
+	if err != nil {
+		if err == cache.ErrObjectNotFound {
+			return playlists, nil
+		}
+
+		return nil, fmt.Errorf("failed to load playlists %s: %s", key, err)
+	}
+
+	if err := json.Unmarshal(raw, &playlists); err != nil {
+		return nil, fmt.Errorf("failed to parse playlists %s: %s", key, err)
+	}
+
+	return playlists, nil
+}
+
+func (s *Store) save(ctx context.Context, key string, playlists map[string]*Playlist) error {
+	raw, err := json.Marshal(playlists)
+	if err != nil {
+		return fmt.Errorf("failed to marshal playlists %s: %s", key, err)
+	}
+
+	if _, err := s.ObjectStore.Put(ctx, key, raw, nil); err != nil {
+		return fmt.Errorf("failed to save playlists %s: %s", key, err)
+	}
+
+	return nil
+}
+
+// List returns the playlists of the user followed by those shared in the guild
+func (s *Store) List(ctx context.Context, guildID, userID string) ([]*Playlist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result []*Playlist
+	for _, key := range scopeKeys(guildID, userID) {
+		playlists, err := s.load(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		var scoped []*Playlist
+		for _, playlist := range playlists {
+			scoped = append(scoped, playlist)
+		}
+
+		sort.Slice(scoped, func(i, j int) bool { return scoped[i].Name < scoped[j].Name })
+		result = append(result, scoped...)
+	}
+
+	return result, nil
+}
+
+// Get finds the playlist by name, preferring the user's own over shared ones
+func (s *Store) Get(ctx context.Context, guildID, userID, name string) (*Playlist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, _, playlist, err := s.find(ctx, guildID, userID, name)
+	return playlist, err
+}
+
+// Lookup gets the playlist from a known location, as used in custom ids
+func (s *Store) Lookup(ctx context.Context, shared bool, id, name string) (*Playlist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if id == "" {
+		return nil, ErrPlaylistNotFound
+	}
+
+	key := userKey(id)
+	if shared {
+		key = guildKey(id)
+	}
+
+	playlists, err := s.load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	playlist, ok := playlists[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrPlaylistNotFound
+	}
+
+	return playlist, nil
+}
+
+func (s *Store) find(ctx context.Context, guildID, userID, name string) (string, map[string]*Playlist, *Playlist, error) {
+	for _, key := range scopeKeys(guildID, userID) {
+		playlists, err := s.load(ctx, key)
+		if err != nil {
+			return "", nil, nil, err
+		}
+
+		if playlist, ok := playlists[strings.ToLower(name)]; ok {
+			return key, playlists, playlist, nil
+		}
+	}
+
+	return "", nil, nil, ErrPlaylistNotFound
+}
+
+// Create saves a new playlist for the user, or for the guild if shared
+func (s *Store) Create(ctx context.Context, guildID, userID, name string, shared bool, tracks []*player.Track) (*Playlist, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("playlist names must be 1 to 32 letters, numbers, spaces, dashes or underscores")
+	}
+
+	if len(tracks) > MaxTracks {
+		return nil, fmt.Errorf("playlists can have at most %d tracks", MaxTracks)
+	}
+
+	if shared && guildID == "" {
+		return nil, fmt.Errorf("playlists can only be shared in a server")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	playlist := Playlist{
+		Name:      name,
+		OwnerID:   userID,
+		Tracks:    tracks,
+		UpdatedAt: time.Now(),
+	}
+
+	if shared {
+		playlist.GuildID = guildID
+	}
+
+	key := playlistKey(&playlist)
+	playlists, err := s.load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := playlists[strings.ToLower(name)]; ok {
+		return nil, ErrPlaylistExists
+	}
+
+	if len(playlists) >= MaxPlaylists {
+		return nil, fmt.Errorf("at most %d playlists can be saved", MaxPlaylists)
+	}
+
+	playlists[strings.ToLower(name)] = &playlist
+	if err := s.save(ctx, key, playlists); err != nil {
+		return nil, err
+	}
+
+	return &playlist, nil
+}
+
+// Update changes a playlist the user created
+func (s *Store) Update(ctx context.Context, guildID, userID, name string, update func(*Playlist) error) (*Playlist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, playlists, playlist, err := s.find(ctx, guildID, userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if playlist.OwnerID != userID {
+		return nil, ErrNotOwner
+	}
+
+	if err := update(playlist); err != nil {
+		return nil, err
+	}
+
+	if len(playlist.Tracks) > MaxTracks {
+		return nil, fmt.Errorf("playlists can have at most %d tracks", MaxTracks)
+	}
+
+	playlist.UpdatedAt = time.Now()
+	if err := s.save(ctx, key, playlists); err != nil {
+		return nil, err
+	}
+
+	return playlist, nil
+}
+
+// Delete removes a playlist the user created
+func (s *Store) Delete(ctx context.Context, guildID, userID, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, playlists, playlist, err := s.find(ctx, guildID, userID, name)
+	if err != nil {
+		return err
+	}
+
+	if playlist.OwnerID != userID {
+		return ErrNotOwner
+	}
+
+	delete(playlists, strings.ToLower(name))
+	return s.save(ctx, key, playlists)
+}
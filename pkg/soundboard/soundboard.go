@@ -0,0 +1,300 @@
+package soundboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/audio"
+	"github.com/axatol/guosheng/pkg/cache"
+	"github.com/axatol/guosheng/pkg/cli"
+	"github.com/axatol/guosheng/pkg/player"
+)
+
+const MaxClips = 50
+
+var (
+	ErrClipNotFound = errors.New("sound not found")
+	ErrClipExists   = errors.New("sound already exists")
+
+	// names are typed out in message commands so cannot contain spaces
+	validName = regexp.MustCompile(`^[\w\-]{1,32}$`)
+)
+
+type Clip struct {
+	Name      string        `json:"name"`
+	CreatorID string        `json:"creator_id"`
+	Title     string        `json:"title"`
+	URL       string        `json:"url"`
+	Start     time.Duration `json:"start"`
+	Duration  time.Duration `json:"duration"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// Soundboard keeps short clips per guild, they are encoded once when added so
+// they can be played straight from the object store
+type Soundboard struct {
+	ObjectStore cache.ObjectStore
+	CLI         *cli.Executor
+	Players     *player.Manager
+	// longest clip that can be added
+	MaxDuration time.Duration
+	mutex       sync.Mutex
+}
+
+func indexKey(guildID string) string {
+	return fmt.Sprintf("guilds/%s/sounds.json", guildID)
+}
+
+func clipKey(guildID, name string) string {
+	return fmt.Sprintf("guilds/%s/sounds/%s.dca", guildID, strings.ToLower(name))
+}
+
+func (s *Soundboard) load(ctx context.Context, guildID string) (map[string]*Clip, error) {
+	clips := map[string]*Clip{}
+	raw, err := s.ObjectStore.Get(ctx, indexKey(guildID))
+	if err != nil {
+		if err == cache.ErrObjectNotFound {
+			return clips, nil
+		}
+
+		return nil, fmt.Errorf("failed to load sounds for guild %s: %s", guildID, err)
+	}
+
+	if err := json.Unmarshal(raw, &clips); err != nil {
+		return nil, fmt.Errorf("failed to parse sounds for guild %s: %s", guildID, err)
+	}
+
+	return clips, nil
+}
+
+func (s *Soundboard) save(ctx context.Context, guildID string, clips map[string]*Clip) error {
+	raw, err := json.Marshal(clips)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sounds for guild %s: %s", guildID, err)
+	}
+
+	if _, err := s.ObjectStore.Put(ctx, indexKey(guildID), raw, nil); err != nil {
+		return fmt.Errorf("failed to save sounds for guild %s: %s", guildID, err)
+	}
+
+	return nil
+}
+
+// List returns the clips of the guild ordered by name
+func (s *Soundboard) List(ctx context.Context, guildID string) ([]*Clip, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clips, err := s.load(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Clip, 0, len(clips))
+	for _, clip := range clips {
+		result = append(result, clip)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// Add resolves the input like a track, trims it to the range and saves the
+// encoded clip, an end of 0 takes as much as the max duration allows
+func (s *Soundboard) Add(ctx context.Context, guildID, userID, name, input string, start, end time.Duration) (*Clip, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("sound names must be 1 to 32 letters, numbers, dashes or underscores")
+	}
+
+	if start < 0 || (end != 0 && end <= start) {
+		return nil, fmt.Errorf("the end must come after the start")
+	}
+
+	if end == 0 {
+		end = start + s.MaxDuration
+	}
+
+	if end-start > s.MaxDuration {
+		return nil, fmt.Errorf("sounds can be at most %s long", s.MaxDuration)
+	}
+
+	// fail early rather than after encoding
+	if _, err := s.Get(ctx, guildID, name); err != ErrClipNotFound {
+		if err == nil {
+			return nil, ErrClipExists
+		}
+
+		return nil, err
+	}
+
+	tracks, err := s.Players.Resolve(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tracks) < 1 {
+		return nil, fmt.Errorf("nothing found for %s", input)
+	}
+
+	track := tracks[0]
+	if track.Live {
+		return nil, fmt.Errorf("sounds cannot be taken from live streams")
+	}
+
+	raw, frames, err := s.encode(ctx, track, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if frames < 1 {
+		return nil, fmt.Errorf("nothing to play between %s and %s", start, end)
+	}
+
+	clip := Clip{
+		Name:      name,
+		CreatorID: userID,
+		Title:     track.Title,
+		URL:       track.URL,
+		Start:     start,
+		Duration:  time.Duration(frames) * audio.FrameDuration,
+		CreatedAt: time.Now(),
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clips, err := s.load(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := clips[strings.ToLower(name)]; ok {
+		return nil, ErrClipExists
+	}
+
+	if len(clips) >= MaxClips {
+		return nil, fmt.Errorf("at most %d sounds can be saved", MaxClips)
+	}
+
+	if _, err := s.ObjectStore.Put(ctx, clipKey(guildID, name), raw, nil); err != nil {
+		return nil, fmt.Errorf("failed to save sound %s: %s", name, err)
+	}
+
+	clips[strings.ToLower(name)] = &clip
+	if err := s.save(ctx, guildID, clips); err != nil {
+		return nil, err
+	}
+
+	return &clip, nil
+}
+
+// encode trims the track and returns the dca encoded clip with its length in
+// frames
+func (s *Soundboard) encode(ctx context.Context, track *player.Track, start, end time.Duration) ([]byte, int, error) {
+	source, err := s.Players.Download(ctx, track)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	encoded, err := s.CLI.EncodeClip(ctx, track.ID, source, start, end)
+	if err != nil {
+		source.Close()
+		return nil, 0, err
+	}
+
+	raw, err := io.ReadAll(encoded)
+
+	// the source must be closed first to unblock the encoder reading from it
+	source.Close()
+	if closeErr := encoded.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to encode sound: %s", err)
+	}
+
+	frames := 0
+	reader := audio.NewDCAReader(bytes.NewReader(raw))
+	for {
+		if _, err := reader.ReadFrame(); err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return nil, 0, fmt.Errorf("failed to read encoded sound: %s", err)
+		}
+
+		frames += 1
+	}
+
+	return raw, frames, nil
+}
+
+func (s *Soundboard) Get(ctx context.Context, guildID, name string) (*Clip, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clips, err := s.load(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	clip, ok := clips[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrClipNotFound
+	}
+
+	return clip, nil
+}
+
+// Remove deletes the clip and its audio
+func (s *Soundboard) Remove(ctx context.Context, guildID, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	clips, err := s.load(ctx, guildID)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := clips[strings.ToLower(name)]; !ok {
+		return ErrClipNotFound
+	}
+
+	delete(clips, strings.ToLower(name))
+	if err := s.save(ctx, guildID, clips); err != nil {
+		return err
+	}
+
+	if err := s.ObjectStore.Delete(ctx, clipKey(guildID, name)); err != nil {
+		return fmt.Errorf("failed to delete sound %s: %s", name, err)
+	}
+
+	return nil
+}
+
+// Play plays the clip in the voice channel, mixed over the current track
+func (s *Soundboard) Play(ctx context.Context, guildID, channelID, name string) (*Clip, error) {
+	clip, err := s.Get(ctx, guildID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := s.ObjectStore.Get(ctx, clipKey(guildID, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sound %s: %s", name, err)
+	}
+
+	s.Players.Get(guildID).PlayClip(channelID, audio.NewDCAReader(bytes.NewReader(raw)))
+	return clip, nil
+}
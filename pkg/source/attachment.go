@@ -0,0 +1,112 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/axatol/guosheng/pkg/cache"
+	"github.com/axatol/guosheng/pkg/player"
+)
+
+const (
+	// larger than discord allows without nitro boosts
+	maxAttachmentSize = 100 << 20
+)
+
+var (
+	_ player.Source = (*Attachment)(nil)
+
+	attachmentHosts = []string{"cdn.discordapp.com", "media.discordapp.net"}
+	// files given to slash commands are uploaded as ephemeral attachments
+	attachmentPaths = []string{"/attachments/", "/ephemeral-attachments/"}
+)
+
+// Attachment plays audio files uploaded to discord, the links expire so the
+// file is cached by its content as soon as it is resolved
+type Attachment struct {
+	ObjectStore cache.ObjectStore
+}
+
+func (s *Attachment) Name() string {
+	return "attachment"
+}
+
+func (s *Attachment) Match(input string) bool {
+	u, err := url.Parse(input)
+	if err != nil {
+		return false
+	}
+
+	if !slices.Contains(attachmentHosts, u.Host) {
+		return false
+	}
+
+	for _, prefix := range attachmentPaths {
+		if strings.HasPrefix(u.Path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Attachment) Resolve(ctx context.Context, input string) ([]*player.Track, error) {
+	u, err := url.Parse(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url %s: %s", input, err)
+	}
+
+	body, err := httpGet(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	defer body.Close()
+	raw, err := io.ReadAll(io.LimitReader(body, maxAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download attachment %s: %s", input, err)
+	}
+
+	if len(raw) > maxAttachmentSize {
+		return nil, fmt.Errorf("attachment %s is larger than %d bytes", input, maxAttachmentSize)
+	}
+
+	sum := sha256.Sum256(raw)
+	track := player.Track{
+		ID:          fmt.Sprintf("attachment-%s", hex.EncodeToString(sum[:])),
+		Title:       fileName(u),
+		URL:         input,
+		Uploader:    "Discord",
+		UploaderURL: "https://discord.com",
+		// the cache key depends on the source, which is otherwise only set
+		// once the track has been resolved
+		Source: s.Name(),
+	}
+
+	// the same file uploaded again is played from the existing copy
+	_, err = s.ObjectStore.Stat(ctx, track.CacheKey())
+	switch err {
+	case nil:
+	case cache.ErrObjectNotFound:
+		if _, err := s.ObjectStore.PutStream(ctx, track.CacheKey(), bytes.NewReader(raw), track.ToMap()); err != nil {
+			return nil, fmt.Errorf("failed to cache attachment %s: %s", input, err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to check cache for attachment %s: %s", input, err)
+	}
+
+	return []*player.Track{&track}, nil
+}
+
+// Open is only needed if the cached copy has gone missing, which only works
+// until the link expires
+func (s *Attachment) Open(ctx context.Context, track *player.Track) (io.ReadCloser, error) {
+	return httpGet(ctx, track.URL)
+}
@@ -0,0 +1,171 @@
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/axatol/guosheng/pkg/player"
+)
+
+var (
+	_ player.LiveSource = (*HTTP)(nil)
+
+	audioExtensions = []string{".mp3", ".ogg", ".opus", ".oga", ".flac", ".wav", ".m4a", ".aac", ".webm"}
+	// hls playlists are only ever played live
+	streamExtensions = []string{".m3u8"}
+)
+
+// HTTP plays audio files and internet radio streams linked directly
+type HTTP struct{}
+
+func (s *HTTP) Name() string {
+	return "http"
+}
+
+func (s *HTTP) Match(input string) bool {
+	return isWebURL(input) && (isAudioFile(input) || isStream(input))
+}
+
+func (s *HTTP) Resolve(ctx context.Context, input string) ([]*player.Track, error) {
+	u, err := url.Parse(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url %s: %s", input, err)
+	}
+
+	track := player.Track{
+		ID:          fmt.Sprintf("http-%s", hashString(input)),
+		Title:       fileName(u),
+		URL:         input,
+		Uploader:    u.Host,
+		UploaderURL: fmt.Sprintf("%s://%s", u.Scheme, u.Host),
+		Live:        isStream(input),
+	}
+
+	if !track.Live {
+		// icecast and shoutcast servers look like plain files until requested
+		header, err := httpHeader(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		if name := header.Get("icy-name"); name != "" {
+			track.Title = name
+			track.Live = true
+		}
+
+		if header.Get("icy-br") != "" || header.Get("icy-metaint") != "" {
+			track.Live = true
+		}
+	}
+
+	return []*player.Track{&track}, nil
+}
+
+func (s *HTTP) LiveURL(ctx context.Context, track *player.Track) (string, error) {
+	return track.URL, nil
+}
+
+func (s *HTTP) Open(ctx context.Context, track *player.Track) (io.ReadCloser, error) {
+	return httpGet(ctx, track.URL)
+}
+
+func httpGet(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %s", url, err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request %s: %s", url, err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("failed to request %s: %s", url, res.Status)
+	}
+
+	return res.Body, nil
+}
+
+// httpHeader returns the response headers for the url without downloading it,
+// servers that do not support HEAD are asked for the first byte instead
+func httpHeader(ctx context.Context, url string) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %s", url, err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request %s: %s", url, err)
+	}
+
+	res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		return res.Header, nil
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %s", url, err)
+	}
+
+	req.Header.Set("Range", "bytes=0-0")
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request %s: %s", url, err)
+	}
+
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("failed to request %s: %s", url, res.Status)
+	}
+
+	return res.Header, nil
+}
+
+func isAudioFile(input string) bool {
+	u, err := url.Parse(input)
+	if err != nil {
+		return false
+	}
+
+	return IsAudioFileName(u.Path)
+}
+
+func isStream(input string) bool {
+	u, err := url.Parse(input)
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(streamExtensions, strings.ToLower(path.Ext(u.Path)))
+}
+
+// IsAudioFileName reports whether the file extension is a known audio format
+func IsAudioFileName(name string) bool {
+	return slices.Contains(audioExtensions, strings.ToLower(path.Ext(name)))
+}
+
+func fileName(u *url.URL) string {
+	name, err := url.PathUnescape(path.Base(u.Path))
+	if err != nil {
+		return path.Base(u.Path)
+	}
+
+	return name
+}
+
+func hashString(input string) string {
+	sum := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(sum[:8])
+}
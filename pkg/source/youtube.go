@@ -0,0 +1,78 @@
+package source
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+
+	"github.com/axatol/guosheng/pkg/cli"
+	"github.com/axatol/guosheng/pkg/player"
+	"github.com/axatol/guosheng/pkg/yt"
+)
+
+var (
+	_ player.LiveSource    = (*YouTube)(nil)
+	_ player.ChapterSource = (*YouTube)(nil)
+
+	youtubeHosts   = []string{"youtube.com", "www.youtube.com", "m.youtube.com", "music.youtube.com", "youtu.be"}
+	youtubeVideoID = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+)
+
+// YouTube plays youtube videos by url or id
+type YouTube struct {
+	YouTube *yt.Client
+	CLI     *cli.Executor
+}
+
+func (s *YouTube) Name() string {
+	return player.DefaultSource
+}
+
+func (s *YouTube) Match(input string) bool {
+	if youtubeVideoID.MatchString(input) {
+		return true
+	}
+
+	u, err := url.Parse(input)
+	if err != nil || !slices.Contains(youtubeHosts, u.Host) {
+		return false
+	}
+
+	return yt.GetVideoIDFromURL(input) != ""
+}
+
+func (s *YouTube) Resolve(ctx context.Context, input string) ([]*player.Track, error) {
+	videoID := input
+	if !youtubeVideoID.MatchString(input) {
+		videoID = yt.GetVideoIDFromURL(input)
+	}
+
+	video, err := s.YouTube.GetVideoByID(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	track := player.NewTrackFromVideo(video)
+	track.Start = yt.GetStartFromURL(input)
+	return []*player.Track{track}, nil
+}
+
+func (s *YouTube) Open(ctx context.Context, track *player.Track) (io.ReadCloser, error) {
+	return s.CLI.Download(ctx, track.ID)
+}
+
+func (s *YouTube) LiveURL(ctx context.Context, track *player.Track) (string, error) {
+	return s.CLI.GetStreamURL(track.URL)
+}
+
+// Chapters are not available from the youtube api so yt-dlp is asked instead
+func (s *YouTube) Chapters(ctx context.Context, track *player.Track) ([]player.Chapter, error) {
+	info, err := s.CLI.GetInfo(track.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return chaptersFromInfo(info), nil
+}
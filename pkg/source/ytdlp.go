@@ -0,0 +1,97 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/cli"
+	"github.com/axatol/guosheng/pkg/player"
+)
+
+var (
+	_ player.LiveSource    = (*YTDLP)(nil)
+	_ player.ChapterSource = (*YTDLP)(nil)
+)
+
+// YTDLP plays any other page yt-dlp can extract audio from, such as
+// soundcloud, bandcamp or vimeo, so it should be checked last
+type YTDLP struct {
+	CLI *cli.Executor
+}
+
+func (s *YTDLP) Name() string {
+	return "ytdlp"
+}
+
+func (s *YTDLP) Match(input string) bool {
+	return isWebURL(input)
+}
+
+func (s *YTDLP) Resolve(ctx context.Context, input string) ([]*player.Track, error) {
+	info, err := s.CLI.GetInfoByURL(input)
+	if err != nil {
+		return nil, err
+	}
+
+	pageURL := info.WebpageURL
+	if pageURL == "" {
+		pageURL = input
+	}
+
+	uploaderURL := info.UploaderURL
+	if uploaderURL == "" {
+		uploaderURL = info.ChannelURL
+	}
+
+	track := player.Track{
+		ID:          fmt.Sprintf("%s-%s", strings.ToLower(info.ExtractorKey), info.ID),
+		Title:       info.Title,
+		URL:         pageURL,
+		Uploader:    info.Uploader,
+		UploaderURL: uploaderURL,
+		Duration:    time.Duration(info.Duration) * time.Second,
+		Live:        info.IsLive,
+		Chapters:    chaptersFromInfo(info),
+	}
+
+	return []*player.Track{&track}, nil
+}
+
+func (s *YTDLP) Open(ctx context.Context, track *player.Track) (io.ReadCloser, error) {
+	return s.CLI.DownloadURL(ctx, track.URL)
+}
+
+func (s *YTDLP) Chapters(ctx context.Context, track *player.Track) ([]player.Chapter, error) {
+	info, err := s.CLI.GetInfoByURL(track.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return chaptersFromInfo(info), nil
+}
+
+func (s *YTDLP) LiveURL(ctx context.Context, track *player.Track) (string, error) {
+	return s.CLI.GetStreamURL(track.URL)
+}
+
+func isWebURL(input string) bool {
+	u, err := url.Parse(input)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func chaptersFromInfo(info *cli.Info) []player.Chapter {
+	chapters := make([]player.Chapter, len(info.Chapters))
+	for i, chapter := range info.Chapters {
+		chapters[i] = player.Chapter{
+			Title: chapter.Title,
+			Start: time.Duration(chapter.StartTime * float64(time.Second)),
+			End:   time.Duration(chapter.EndTime * float64(time.Second)),
+		}
+	}
+
+	return chapters
+}
@@ -0,0 +1,100 @@
+package sponsorblock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/player"
+)
+
+const (
+	// other actions mute or mark segments rather than skip them
+	actionSkip = "skip"
+)
+
+var (
+	_ player.SegmentProvider = (*Client)(nil)
+)
+
+type Segment struct {
+	UUID       string     `json:"UUID"`
+	Category   string     `json:"category"`
+	ActionType string     `json:"actionType"`
+	Segment    [2]float64 `json:"segment"`
+}
+
+// Client talks to a sponsorblock compatible api
+type Client struct {
+	BaseURL string
+}
+
+// GetSegments returns the skip segments of the youtube video in the given
+// categories, a video without any segments is not an error
+func (c *Client) GetSegments(ctx context.Context, videoID string, categories []string) ([]Segment, error) {
+	rawCategories, err := json.Marshal(categories)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal categories: %s", err)
+	}
+
+	query := url.Values{}
+	query.Set("videoID", videoID)
+	query.Set("categories", string(rawCategories))
+	endpoint := fmt.Sprintf("%s/api/skipSegments?%s", c.BaseURL, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for segments of %s: %s", videoID, err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request segments of %s: %s", videoID, err)
+	}
+
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("failed to request segments of %s: %s", videoID, res.Status)
+	}
+
+	var segments []Segment
+	if err := json.NewDecoder(res.Body).Decode(&segments); err != nil {
+		return nil, fmt.Errorf("failed to parse segments of %s: %s", videoID, err)
+	}
+
+	return segments, nil
+}
+
+// Segments returns the skippable segments of youtube tracks in every category
+func (c *Client) Segments(ctx context.Context, track *player.Track) ([]player.Segment, error) {
+	if track.Source != "" && track.Source != player.DefaultSource {
+		return nil, nil
+	}
+
+	segments, err := c.GetSegments(ctx, track.ID, player.SegmentCategories)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []player.Segment
+	for _, segment := range segments {
+		if segment.ActionType != "" && segment.ActionType != actionSkip {
+			continue
+		}
+
+		result = append(result, player.Segment{
+			Category: segment.Category,
+			Start:    time.Duration(segment.Segment[0] * float64(time.Second)),
+			End:      time.Duration(segment.Segment[1] * float64(time.Second)),
+		})
+	}
+
+	return result, nil
+}
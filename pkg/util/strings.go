@@ -14,3 +14,24 @@ func Obscure(input string, start int, end ...int) string {
 
 	return b.String()
 }
+
+// ProgressBar renders a text progress bar of the given width
+func ProgressBar(value, total float64, width int) string {
+	position := 0
+	if total > 0 {
+		position = int(value / total * float64(width-1))
+	}
+
+	position = max(0, min(position, width-1))
+
+	var b strings.Builder
+	for i := 0; i < width; i++ {
+		if i == position {
+			b.WriteString("🔘")
+		} else {
+			b.WriteString("▬")
+		}
+	}
+
+	return b.String()
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -66,3 +67,55 @@ func ParseISODuration(input string) (*ISODuration, error) {
 
 	return &d, nil
 }
+
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	hours := int(d / time.Hour)
+	minutes := int(d%time.Hour) / int(time.Minute)
+	seconds := int(d%time.Minute) / int(time.Second)
+
+	result := fmt.Sprintf("%02d:%02d", minutes, seconds)
+	if hours > 0 {
+		result = fmt.Sprintf("%02d:%s", hours, result)
+	}
+
+	return result
+}
+
+// ParseTimestamp parses positions such as "90", "1:30", "01:02:03" or "1m30s"
+func ParseTimestamp(input string) (time.Duration, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return 0, fmt.Errorf("empty timestamp")
+	}
+
+	if seconds, err := strconv.Atoi(input); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	if !strings.Contains(input, ":") {
+		d, err := time.ParseDuration(input)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse timestamp %s: %s", input, err)
+		}
+
+		return d, nil
+	}
+
+	parts := strings.Split(input, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("failed to parse timestamp %s: too many segments", input)
+	}
+
+	var d time.Duration
+	for _, part := range parts {
+		val, err := strconv.Atoi(part)
+		if err != nil || val < 0 {
+			return 0, fmt.Errorf("failed to parse timestamp segment %s", part)
+		}
+
+		d = d*60 + time.Duration(val)
+	}
+
+	return d * time.Second, nil
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
+
+	"github.com/axatol/guosheng/pkg/util"
 
 	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
@@ -33,10 +36,38 @@ func NormaliseURL(raw string) string {
 }
 
 func GetVideoIDFromURL(input string) string {
-	url, err := url.Parse(input)
+	// short links carry the id in the path and may have their own query
+	if short, err := url.Parse(input); err == nil && short.Host == "youtu.be" {
+		return strings.TrimPrefix(short.Path, "/")
+	}
+
+	url, err := url.Parse(NormaliseURL(input))
 	if err != nil {
 		return ""
 	}
 
-	return url.Query().Get("q")
+	return url.Query().Get("v")
+}
+
+// GetStartFromURL returns the position given by the t or start parameters
+func GetStartFromURL(input string) time.Duration {
+	url, err := url.Parse(NormaliseURL(input))
+	if err != nil {
+		return 0
+	}
+
+	for _, key := range []string{"t", "start"} {
+		if !url.Query().Has(key) {
+			continue
+		}
+
+		start, err := util.ParseTimestamp(url.Query().Get(key))
+		if err != nil {
+			return 0
+		}
+
+		return start
+	}
+
+	return 0
 }
@@ -0,0 +1,124 @@
+package yt
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// the maximum page size accepted by the youtube api
+	maxResultsPerPage = 50
+)
+
+type Playlist struct {
+	ID           string `json:"id"`
+	ETag         string `json:"etag"`
+	Title        string `json:"title"`
+	ChannelID    string `json:"channel_id"`
+	ChannelTitle string `json:"channel_title"`
+	ItemCount    int64  `json:"item_count"`
+}
+
+func (p *Playlist) PlaylistURL() string {
+	return fmt.Sprintf("https://youtube.com/playlist?list=%s", p.ID)
+}
+
+func (p *Playlist) ChannelURL() string {
+	return fmt.Sprintf("https://youtube.com/channel/%s", p.ChannelID)
+}
+
+func GetPlaylistIDFromURL(input string) string {
+	url, err := url.Parse(NormaliseURL(input))
+	if err != nil || !strings.HasSuffix(url.Host, "youtube.com") {
+		return ""
+	}
+
+	return url.Query().Get("list")
+}
+
+func (c *Client) GetPlaylistByID(ctx context.Context, id string) (*Playlist, error) {
+	query := c.service.Playlists.
+		List([]string{"snippet", "contentDetails"}).
+		Context(ctx).
+		Id(id)
+
+	response, err := query.Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query playlist by id %s: %s", id, err)
+	}
+
+	if len(response.Items) < 1 {
+		return nil, fmt.Errorf("playlist by id %s not found", id)
+	}
+
+	item := response.Items[0]
+	playlist := Playlist{
+		ID:           item.Id,
+		ETag:         item.Etag,
+		Title:        item.Snippet.Title,
+		ChannelID:    item.Snippet.ChannelId,
+		ChannelTitle: item.Snippet.ChannelTitle,
+		ItemCount:    item.ContentDetails.ItemCount,
+	}
+
+	return &playlist, nil
+}
+
+// GetPlaylistVideos returns up to limit videos from the playlist in order,
+// along with the number of items that are no longer available
+func (c *Client) GetPlaylistVideos(ctx context.Context, id string, limit int) ([]Video, int, error) {
+	var ids []string
+	pageToken := ""
+	for len(ids) < limit {
+		query := c.service.PlaylistItems.
+			List([]string{"contentDetails"}).
+			Context(ctx).
+			PlaylistId(id).
+			MaxResults(maxResultsPerPage).
+			PageToken(pageToken)
+
+		response, err := query.Do()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to query items of playlist %s: %s", id, err)
+		}
+
+		for _, item := range response.Items {
+			if len(ids) >= limit {
+				break
+			}
+
+			ids = append(ids, item.ContentDetails.VideoId)
+		}
+
+		if response.NextPageToken == "" {
+			break
+		}
+
+		pageToken = response.NextPageToken
+	}
+
+	// private and deleted videos are listed but cannot be looked up
+	found := map[string]Video{}
+	for start := 0; start < len(ids); start += maxResultsPerPage {
+		end := min(start+maxResultsPerPage, len(ids))
+		videos, err := c.listVideos(ctx, ids[start:end]...)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		for _, video := range videos {
+			found[video.ID] = video
+		}
+	}
+
+	result := make([]Video, 0, len(found))
+	for _, id := range ids {
+		if video, ok := found[id]; ok {
+			result = append(result, video)
+		}
+	}
+
+	return result, len(ids) - len(result), nil
+}
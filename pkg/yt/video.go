@@ -12,12 +12,13 @@ import (
 )
 
 type Video struct {
-	ID           string `json:"id"`
-	ETag         string `json:"etag"`
-	Title        string `json:"title"`
-	ChannelID    string `json:"channel_id"`
-	ChannelTitle string `json:"channel_title"`
-	DurationRaw  string `json:"duration"`
+	ID                   string `json:"id"`
+	ETag                 string `json:"etag"`
+	Title                string `json:"title"`
+	ChannelID            string `json:"channel_id"`
+	ChannelTitle         string `json:"channel_title"`
+	DurationRaw          string `json:"duration"`
+	LiveBroadcastContent string `json:"live_broadcast_content"`
 }
 
 func (v *Video) ToMap() map[string]string {
@@ -31,6 +32,10 @@ func (v *Video) ToMap() map[string]string {
 	}
 }
 
+func (v *Video) IsLive() bool {
+	return v.LiveBroadcastContent == "live"
+}
+
 func (v *Video) VideoURL() string {
 	return fmt.Sprintf("https://youtube.com/watch?v=%s", v.ID)
 }
@@ -68,6 +73,19 @@ func (v *Video) AsMessageEmbed() *discordgo.MessageEmbed {
 }
 
 func (c *Client) GetVideosByIDs(ctx context.Context, ids ...string) ([]Video, error) {
+	result, err := c.listVideos(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) < 1 {
+		return nil, fmt.Errorf("video by id %s not found", strings.Join(ids, ","))
+	}
+
+	return result, nil
+}
+
+func (c *Client) listVideos(ctx context.Context, ids ...string) ([]Video, error) {
 	query := c.service.Videos.
 		List([]string{"snippet", "contentDetails"}).
 		Context(ctx).
@@ -79,19 +97,16 @@ func (c *Client) GetVideosByIDs(ctx context.Context, ids ...string) ([]Video, er
 		return nil, fmt.Errorf("failed to query video by id %s: %s", strings.Join(ids, ","), err)
 	}
 
-	if len(response.Items) < 1 {
-		return nil, fmt.Errorf("video by id %s not found", strings.Join(ids, ","))
-	}
-
 	result := make([]Video, len(response.Items))
 	for i, item := range response.Items {
 		result[i] = Video{
-			ID:           item.Id,
-			ETag:         item.Etag,
-			Title:        item.Snippet.Title,
-			ChannelID:    item.Snippet.ChannelId,
-			ChannelTitle: item.Snippet.ChannelTitle,
-			DurationRaw:  item.ContentDetails.Duration,
+			ID:                   item.Id,
+			ETag:                 item.Etag,
+			Title:                item.Snippet.Title,
+			ChannelID:            item.Snippet.ChannelId,
+			ChannelTitle:         item.Snippet.ChannelTitle,
+			DurationRaw:          item.ContentDetails.Duration,
+			LiveBroadcastContent: item.Snippet.LiveBroadcastContent,
 		}
 	}
 
@@ -134,12 +149,13 @@ func (c *Client) SearchVideo(ctx context.Context, search string, limit int64) ([
 	result := make([]Video, len(response.Items))
 	for i, item := range response.Items {
 		result[i] = Video{
-			ID:           item.Id.VideoId,
-			ETag:         item.Etag,
-			Title:        item.Snippet.Title,
-			ChannelID:    item.Snippet.ChannelId,
-			ChannelTitle: item.Snippet.ChannelTitle,
-			DurationRaw:  "",
+			ID:                   item.Id.VideoId,
+			ETag:                 item.Etag,
+			Title:                item.Snippet.Title,
+			ChannelID:            item.Snippet.ChannelId,
+			ChannelTitle:         item.Snippet.ChannelTitle,
+			DurationRaw:          "",
+			LiveBroadcastContent: item.Snippet.LiveBroadcastContent,
 		}
 	}
 